@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"slices"
+
+	"github.com/khueue/ifrit/internal/config"
+	"github.com/khueue/ifrit/internal/docker"
+	"github.com/spf13/cobra"
+)
+
+// loadCompletionConfig loads the config for shell completion, which runs
+// without PersistentPreRunE. It never fails: a missing or invalid config
+// simply results in no suggestions, rather than errors in the user's shell.
+func loadCompletionConfig() bool {
+	if cfg != nil {
+		return true
+	}
+	c, err := config.Load(configPath)
+	if err != nil {
+		return false
+	}
+	cfg = c
+	manager = docker.NewManager(cfg, false)
+	return true
+}
+
+// completeProjects completes project names, skipping those already given.
+func completeProjects(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if !loadCompletionConfig() {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var names []string
+	for _, name := range cfg.GetProjects() {
+		if !slices.Contains(args, name) {
+			names = append(names, name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeProjectService completes "<project> <service>" positional pairs.
+func completeProjectService(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return completeProjects(cmd, args, toComplete)
+	case 1:
+		if !loadCompletionConfig() {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		services, err := manager.ComposeServices(args[0])
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return services, cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTasks completes task names for the first argument only.
+func completeTasks(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 || !loadCompletionConfig() {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return cfg.GetTasks(), cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var cpIndex int
+
+// copyEndpoint is one side of a copy: a path in a service container, or a
+// host path when target is nil.
+type copyEndpoint struct {
+	target *target
+	path   string
+}
+
+// parseCopyEndpoint parses "project/service:path" or a host path. Anything
+// whose prefix doesn't name a configured project/service pair is treated as
+// a host path, so host paths containing colons still work.
+func parseCopyEndpoint(arg string) copyEndpoint {
+	ref, p, ok := strings.Cut(arg, ":")
+	if ok {
+		projectName, serviceName, ok := strings.Cut(ref, "/")
+		if _, exists := cfg.Projects[projectName]; ok && exists && serviceName != "" {
+			return copyEndpoint{target: &target{projectName, serviceName}, path: p}
+		}
+	}
+	return copyEndpoint{path: arg}
+}
+
+// absHostPath makes a host path absolute for "docker compose cp". A trailing
+// "/." (the contents of a directory) or "/" means something to docker cp, so
+// it is kept rather than cleaned away. "-" (stdin or stdout) is passed
+// through.
+func absHostPath(p string) (string, error) {
+	if p == "-" {
+		return p, nil
+	}
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
+
+	sep := string(filepath.Separator)
+	switch {
+	case filepath.Base(p) == ".":
+		abs = strings.TrimSuffix(abs, sep) + sep + "."
+	case strings.HasSuffix(p, sep):
+		abs = strings.TrimSuffix(abs, sep) + sep
+	}
+	return abs, nil
+}
+
+var cpCmd = &cobra.Command{
+	Use:   "cp <src> <dst>",
+	Short: "Copy files between the host and services",
+	Long: `Copy files or directories between the host and a service container, or
+between two services (possibly in different projects).
+
+Container paths are written as project/service:path. The container is found
+through the project's compose setup, so there's no need to know the generated
+compose project name (<name_prefix>_<project>).
+
+A host path of "-" streams a tar archive from stdin or to stdout, as with
+docker cp. A source path ending in "/." copies the contents of the directory
+rather than the directory itself.
+
+Copies between two services go through a temporary directory on the host.
+--index selects the replica of a scaled service, on every container side of
+the copy.`,
+	Example: `  # Copy logs out of a container
+  ifrit cp backend/api:/app/logs ./logs
+
+  # Copy a file into a container
+  ifrit cp ./config.json backend/api:/app/config.json
+
+  # Copy between services in different projects
+  ifrit cp backend/api:/app/export.sql database/postgres:/tmp/export.sql
+
+  # Stream a directory as a tar archive
+  ifrit cp backend/api:/app/uploads - | tar -tv`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		src := parseCopyEndpoint(args[0])
+		dst := parseCopyEndpoint(args[1])
+
+		// Compose runs in the project directory, so host paths must be
+		// resolved against the current directory first.
+		for _, e := range []*copyEndpoint{&src, &dst} {
+			if e.target == nil {
+				p, err := absHostPath(e.path)
+				if err != nil {
+					return err
+				}
+				e.path = p
+			}
+		}
+
+		switch {
+		case src.target == nil && dst.target == nil:
+			return fmt.Errorf("at least one side must be a container path (project/service:path)")
+		case src.target == nil:
+			return manager.ComposeCopy(dst.target.project, src.path, dst.target.service+":"+dst.path, cpIndex)
+		case dst.target == nil:
+			return manager.ComposeCopy(src.target.project, src.target.service+":"+src.path, dst.path, cpIndex)
+		}
+
+		// Service to service: stage the files in a temporary directory,
+		// keeping the source's base name so that copy semantics match a
+		// direct copy.
+		tmpDir, err := os.MkdirTemp("", "ifrit-cp-")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		defer os.RemoveAll(tmpDir)
+
+		name := path.Base(strings.TrimRight(src.path, "/"))
+		contents := name == "."
+		if contents {
+			// "dir/." copies the contents of dir: stage them in a directory
+			// of their own, and copy its contents in turn.
+			name = "contents"
+		}
+		staged := filepath.Join(tmpDir, name)
+		if err := manager.ComposeCopy(src.target.project, src.target.service+":"+src.path, staged, cpIndex); err != nil {
+			return err
+		}
+		if contents {
+			staged += string(filepath.Separator) + "."
+		}
+		return manager.ComposeCopy(dst.target.project, staged, dst.target.service+":"+dst.path, cpIndex)
+	},
+}
+
+func init() {
+	cpCmd.Flags().IntVar(&cpIndex, "index", 0, "Container index for services with multiple replicas (applies to both sides)")
+	rootCmd.AddCommand(cpCmd)
+}
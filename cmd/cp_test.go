@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestAbsHostPath(t *testing.T) {
+	t.Chdir(t.TempDir())
+	// The working directory as the process sees it, e.g. with symlinks.
+	wd, err := filepath.Abs(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"-", "-"},
+		{"logs", wd + "/logs"},
+		{"./logs/", wd + "/logs/"},
+		{"./build/.", wd + "/build/."},
+		{"build/./", wd + "/build/."},
+		{".", wd + "/."},
+		{"./a/../b", wd + "/b"},
+		{"/srv/data", "/srv/data"},
+		{"/srv/data/", "/srv/data/"},
+		{"/srv/data/.", "/srv/data/."},
+		{"/", "/"},
+	}
+	for _, tt := range tests {
+		got, err := absHostPath(tt.path)
+		if err != nil {
+			t.Fatalf("absHostPath(%q): %v", tt.path, err)
+		}
+		if got != tt.want {
+			t.Errorf("absHostPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
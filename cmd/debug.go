@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+
+	"github.com/khueue/ifrit/internal/docker"
+	"github.com/khueue/ifrit/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	debugImage   string
+	debugCleanup bool
+)
+
+var debugCmd = &cobra.Command{
+	Use:   "debug <project> <service>",
+	Short: "Attach a debug sidecar to a container without a shell",
+	Long: `Start a temporary toolbox container that shares the PID and network
+namespaces of a service's container, and open an interactive shell in it.
+
+This is meant for distroless or scratch-based images that can't be entered
+with 'ifrit shell'. The target's processes are visible with ps, its network is
+shared, and its filesystem is available under /proc/1/root (where the shell
+starts). The sidecar is removed when the session ends.
+
+The toolbox image defaults to the debug_image setting in ifrit.yml, or
+busybox. Use --cleanup to remove leftover sidecars.`,
+	Example: `  # Debug the backend api service
+  ifrit debug backend api
+
+  # Use a network-focused toolbox image
+  ifrit debug --image nicolaka/netshoot backend api
+
+  # Remove leftover debug sidecars
+  ifrit debug --cleanup`,
+	ValidArgsFunction: completeProjectService,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if debugCleanup {
+			n, err := manager.CleanupDebug()
+			if err != nil {
+				return err
+			}
+			ui.Printf("Removed %d debug container(s).\n", n)
+			return nil
+		}
+
+		if len(args) != 2 {
+			return fmt.Errorf("requires a project and service name")
+		}
+
+		image := debugImage
+		if image == "" {
+			image = cfg.DebugImage
+		}
+		if image == "" {
+			image = docker.DefaultDebugImage
+		}
+
+		if err := manager.Debug(args[0], args[1], image, isTerminal()); err != nil {
+			if exitErr, ok := errors.AsType[*exec.ExitError](err); ok {
+				return &SilentExitError{Code: exitErr.ExitCode()}
+			}
+			return err
+		}
+		return nil
+	},
+}
+
+func init() {
+	debugCmd.Flags().StringVar(&debugImage, "image", "", "Toolbox image to use (overrides debug_image)")
+	debugCmd.Flags().BoolVar(&debugCleanup, "cleanup", false, "Remove leftover debug sidecars")
+	rootCmd.AddCommand(debugCmd)
+}
@@ -23,6 +23,7 @@ stops all projects.`,
 
   # Stop projects and remove volumes
   ifrit down --volumes backend`,
+	ValidArgsFunction: completeProjects,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 || downAll {
 			if len(cfg.GetProjects()) == 0 {
@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+
+	"github.com/khueue/ifrit/internal/docker"
+	"github.com/khueue/ifrit/internal/ui"
+	"github.com/khueue/ifrit/internal/ui/picker"
+	"github.com/spf13/cobra"
+)
+
+var execParallel bool
+
+// prefixWriter writes complete lines to out, each prefixed with a label.
+// Writers sharing a mutex never interleave within a line.
+type prefixWriter struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.writeLine(w.buf[:i+1])
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes any trailing partial line.
+func (w *prefixWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.writeLine(append(w.buf, '\n'))
+		w.buf = nil
+	}
+}
+
+func (w *prefixWriter) writeLine(line []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	fmt.Fprintf(w.out, "%s%s", w.prefix, line)
+}
+
+// execResult is the outcome of running the command in one target.
+type execResult struct {
+	target  target
+	skipped bool // not running
+	err     error
+}
+
+func (r execResult) summary() string {
+	switch {
+	case r.skipped:
+		return "skipped (not running)"
+	case r.err == nil:
+		return "ok"
+	}
+	if exitErr, ok := errors.AsType[*exec.ExitError](r.err); ok {
+		return fmt.Sprintf("exit %d", exitErr.ExitCode())
+	}
+	return fmt.Sprintf("error: %v", r.err)
+}
+
+var execCmd = &cobra.Command{
+	Use:   "exec <selector>... -- command [args...]",
+	Short: "Run a command in every service matching a selector",
+	Long: `Run the same command in every running service matching one or more
+selectors, and print a per-service summary.
+
+A selector is a "project/service" glob pattern such as "*/api" or "backend/*",
+or a bare project name matching all of its services. If no selector is given
+in a terminal, a fuzzy picker lists all services to choose one. Services that aren't
+running are skipped. Each output line is prefixed with its project/service.
+
+Targets run one after another by default, or all at once with --parallel.
+The exit status is non-zero if the command failed in any target.`,
+	Example: `  # Show the environment of every api service
+  ifrit exec '*/api' -- env
+
+  # Check DNS resolution from all backend services at once
+  ifrit exec --parallel backend -- getent hosts myapp_database
+
+  # Pick a service and list its processes
+  ifrit exec -- ps aux
+
+  # Run a compound shell expression
+  ifrit exec '*' -- "echo \$HOSTNAME && id"`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		selectors, commandArgs, dash := splitAtDash(cmd, args)
+		if !dash || len(commandArgs) == 0 {
+			return fmt.Errorf("use '--' to separate the command from the selectors:\n  ifrit exec '*/api' -- env")
+		}
+
+		// In a terminal, let the user pick a service if none was selected.
+		if len(selectors) == 0 && isTerminal() {
+			projectName, serviceName, err := pickService("")
+			if errors.Is(err, picker.ErrCancelled) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			selectors = []string{projectName + "/" + serviceName}
+		}
+		if len(selectors) == 0 {
+			return fmt.Errorf("requires at least one selector")
+		}
+
+		command := commandArgs
+		if len(commandArgs) == 1 {
+			// Same as "shell": allow shell expressions as a single argument.
+			command = []string{"sh", "-c", commandArgs[0]}
+		}
+
+		targets, err := matchTargets(selectors)
+		if err != nil {
+			return err
+		}
+		if len(targets) == 0 {
+			return fmt.Errorf("no services match %v", selectors)
+		}
+
+		width := 0
+		for _, t := range targets {
+			width = max(width, len(t.String()))
+		}
+
+		results := runFanOut(targets, command, width)
+
+		ui.Println()
+		ui.Println("Summary:")
+		failed := false
+		for _, r := range results {
+			ui.Printf("  %-*s  %s\n", width, r.target, r.summary())
+			failed = failed || r.err != nil
+		}
+
+		if failed {
+			return &SilentExitError{Code: 1}
+		}
+		return nil
+	},
+}
+
+// runFanOut runs the command in every running target, sequentially or in
+// parallel, and returns one result per target in the original order. Output
+// lines are prefixed with the target, padded to width.
+func runFanOut(targets []target, command []string, width int) []execResult {
+	results := make([]execResult, len(targets))
+
+	// Look up running services once per project. If that fails, the
+	// command fails in each of the project's targets.
+	running := map[string]map[string]string{}
+	stateErrs := map[string]error{}
+	for _, t := range targets {
+		if _, ok := running[t.project]; !ok {
+			running[t.project], stateErrs[t.project] = manager.ServiceStates(t.project)
+		}
+	}
+
+	var mu sync.Mutex
+	run := func(i int) {
+		t := targets[i]
+		results[i].target = t
+
+		if err := stateErrs[t.project]; err != nil {
+			results[i].err = err
+			return
+		}
+		if running[t.project][t.service] != "running" {
+			results[i].skipped = true
+			return
+		}
+
+		c, err := manager.ComposeExecCmd(t.project, t.service, command, docker.ExecOptions{})
+		if err != nil {
+			results[i].err = err
+			return
+		}
+
+		prefix := fmt.Sprintf("%-*s | ", width, t)
+		stdout := &prefixWriter{mu: &mu, out: os.Stdout, prefix: prefix}
+		stderr := &prefixWriter{mu: &mu, out: os.Stderr, prefix: prefix}
+		c.Stdout = stdout
+		c.Stderr = stderr
+
+		results[i].err = c.Run()
+		stdout.Flush()
+		stderr.Flush()
+	}
+
+	if !execParallel {
+		for i := range targets {
+			run(i)
+		}
+		return results
+	}
+
+	var wg sync.WaitGroup
+	for i := range targets {
+		wg.Go(func() { run(i) })
+	}
+	wg.Wait()
+	return results
+}
+
+func init() {
+	execCmd.Flags().BoolVarP(&execParallel, "parallel", "p", false, "Run in all targets at the same time")
+	execCmd.Flags().SetInterspersed(false)
+	rootCmd.AddCommand(execCmd)
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/khueue/ifrit/internal/docker"
+	"github.com/spf13/cobra"
+)
+
+var (
+	forwardImage   string
+	forwardAddress string
+)
+
+// parseForwardSpec parses "<local-port>:<host>:<port>", or "<port>:<host>"
+// when both ports are the same.
+func parseForwardSpec(spec string) (int, string, int, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) == 2 {
+		parts = append(parts, parts[0])
+	}
+	if len(parts) != 3 || parts[1] == "" {
+		return 0, "", 0, fmt.Errorf("invalid forward %q, expected <local-port>:<container>:<port>", spec)
+	}
+
+	localPort, err := strconv.Atoi(parts[0])
+	if err != nil || localPort < 1 || localPort > 65535 {
+		return 0, "", 0, fmt.Errorf("invalid local port %q", parts[0])
+	}
+	port, err := strconv.Atoi(parts[2])
+	if err != nil || port < 1 || port > 65535 {
+		return 0, "", 0, fmt.Errorf("invalid container port %q", parts[2])
+	}
+	return localPort, parts[1], port, nil
+}
+
+var forwardCmd = &cobra.Command{
+	Use:   "forward <local-port>:<container>:<port>",
+	Short: "Forward a host port to a container on the shared network",
+	Long: `Forward a host port to a port on any container reachable on the shared
+network, by container name or network alias, even if it doesn't publish any
+ports. Useful for pointing GUI clients at databases.
+
+A small relay container (socat) is started on the shared network and removed
+when the command exits (e.g. on Ctrl-C). The relay image defaults to the
+forward_image setting in ifrit.yml, or alpine/socat.`,
+	Example: `  # Connect to a database that doesn't publish its port
+  ifrit forward 5432:myapp_database:5432
+
+  # Same port on both sides
+  ifrit forward 6379:myapp_redis
+
+  # Listen on all interfaces instead of localhost
+  ifrit forward --address 0.0.0.0 8080:myapp_backend:8080`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		localPort, host, port, err := parseForwardSpec(args[0])
+		if err != nil {
+			return err
+		}
+
+		image := forwardImage
+		if image == "" {
+			image = cfg.ForwardImage
+		}
+		if image == "" {
+			image = docker.DefaultForwardImage
+		}
+
+		return manager.Forward(forwardAddress, localPort, host, port, image)
+	},
+}
+
+func init() {
+	forwardCmd.Flags().StringVar(&forwardImage, "image", "", "Relay image to use, with socat as entrypoint (overrides forward_image)")
+	forwardCmd.Flags().StringVar(&forwardAddress, "address", "127.0.0.1", "Host address to listen on")
+	rootCmd.AddCommand(forwardCmd)
+}
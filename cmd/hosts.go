@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/khueue/ifrit/internal/docker"
+	"github.com/khueue/ifrit/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+const systemHostsFile = "/etc/hosts"
+
+var (
+	hostsWrite    bool
+	hostsFile     string
+	hostsRemove   bool
+	hostsWatch    bool
+	hostsLoopback bool
+	hostsFormat   string
+)
+
+// hostsMarkers returns the lines delimiting ifrit's block for this config,
+// so that several ifrit setups can share one file.
+func hostsMarkers() (string, string) {
+	return "# BEGIN ifrit " + cfg.NamePrefix, "# END ifrit " + cfg.NamePrefix
+}
+
+// renderHostsBlock renders the entries between the markers, in hosts or
+// dnsmasq format.
+func renderHostsBlock(entries []docker.HostEntry) string {
+	begin, end := hostsMarkers()
+
+	var b strings.Builder
+	b.WriteString(begin + "\n")
+	for _, e := range entries {
+		if hostsFormat == "dnsmasq" {
+			fmt.Fprintf(&b, "host-record=%s,%s\n", e.Name, e.Address)
+		} else {
+			fmt.Fprintf(&b, "%s\t%s\n", e.Address, e.Name)
+		}
+	}
+	b.WriteString(end + "\n")
+	return b.String()
+}
+
+// replaceHostsBlock replaces ifrit's block in content with block, appending
+// it if there is none. An empty block removes the existing one.
+func replaceHostsBlock(content, block string) string {
+	begin, end := hostsMarkers()
+
+	var kept []string
+	inBlock := false
+	for line := range strings.Lines(content) {
+		switch strings.TrimSpace(line) {
+		case begin:
+			inBlock = true
+			continue
+		case end:
+			inBlock = false
+			continue
+		}
+		if !inBlock {
+			kept = append(kept, line)
+		}
+	}
+
+	out := strings.Join(kept, "")
+	if block == "" {
+		return out
+	}
+	if out != "" && !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	return out + block
+}
+
+// writeHostsBlock updates ifrit's block in path, creating the file if
+// needed. It reports whether the file changed.
+func writeHostsBlock(path, block string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+
+	updated := replaceHostsBlock(string(data), block)
+	if updated == string(data) {
+		return false, nil
+	}
+
+	// Write in place rather than replacing the file, which keeps its
+	// permissions and works for bind-mounted files such as /etc/hosts.
+	if err := os.WriteFile(path, []byte(updated), 0o644); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// syncHosts regenerates the block and writes it to path, or prints it if
+// path is empty.
+func syncHosts(path string) error {
+	entries, err := manager.HostEntries(hostsLoopback)
+	if err != nil {
+		return err
+	}
+	block := renderHostsBlock(entries)
+
+	if path == "" {
+		fmt.Print(block)
+		return nil
+	}
+
+	changed, err := writeHostsBlock(path, block)
+	if err != nil {
+		return err
+	}
+	if changed {
+		ui.Printf("Updated %s with %d entries\n", path, len(entries))
+	}
+	return nil
+}
+
+var hostsCmd = &cobra.Command{
+	Use:   "hosts",
+	Short: "Sync container names on the shared network into a hosts file",
+	Long: `Generate hosts-file entries mapping every container name and alias on the
+shared network to its IP, so that host-side tools can use the same names as
+containers (e.g. myapp_database).
+
+By default the block is printed. Use --write to update /etc/hosts (usually
+requires root), or --file to update any other file, such as one included by
+dnsmasq via addn-hosts. Only the block between the ifrit markers is touched.
+
+With --watch, the file is kept up to date as containers join and leave the
+shared network.`,
+	Example: `  # Print the hosts block
+  ifrit hosts
+
+  # Update /etc/hosts and keep it in sync
+  sudo ifrit hosts --write --watch
+
+  # Write to a custom file for dnsmasq, without root
+  ifrit hosts --file ~/.config/dnsmasq/ifrit.hosts
+
+  # Map services with published ports to 127.0.0.1 (e.g. Docker Desktop)
+  ifrit hosts --loopback-published --write
+
+  # Remove the block again
+  sudo ifrit hosts --write --remove`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if hostsFormat != "hosts" && hostsFormat != "dnsmasq" {
+			return fmt.Errorf("invalid format %q, expected hosts or dnsmasq", hostsFormat)
+		}
+
+		path := hostsFile
+		if path == "" && hostsWrite {
+			path = systemHostsFile
+		}
+
+		if hostsRemove {
+			if path == "" {
+				return fmt.Errorf("--remove requires --write or --file")
+			}
+			if _, err := writeHostsBlock(path, ""); err != nil {
+				return err
+			}
+			ui.Printf("Removed ifrit entries from %s\n", path)
+			return nil
+		}
+
+		if err := syncHosts(path); err != nil {
+			return err
+		}
+		if !hostsWatch {
+			return nil
+		}
+		if path == "" {
+			return fmt.Errorf("--watch requires --write or --file")
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		// Events arrive in bursts (e.g. "ifrit up"), so sync once things
+		// have settled.
+		changes := make(chan struct{}, 1)
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-changes:
+				}
+				time.Sleep(500 * time.Millisecond)
+				if err := syncHosts(path); err != nil {
+					ui.Printf("Warning: %v\n", err)
+				}
+			}
+		}()
+
+		ui.Printf("Watching %s for changes (Ctrl-C to stop)\n", cfg.SharedNetwork)
+		return manager.WatchNetwork(ctx, func() {
+			select {
+			case changes <- struct{}{}:
+			default:
+			}
+		})
+	},
+}
+
+func init() {
+	hostsCmd.Flags().BoolVar(&hostsWrite, "write", false, "Update "+systemHostsFile)
+	hostsCmd.Flags().StringVar(&hostsFile, "file", "", "Update this file instead of "+systemHostsFile)
+	hostsCmd.Flags().BoolVar(&hostsRemove, "remove", false, "Remove the ifrit block instead of updating it")
+	hostsCmd.Flags().BoolVar(&hostsWatch, "watch", false, "Keep the file updated from Docker events")
+	hostsCmd.Flags().BoolVar(&hostsLoopback, "loopback-published", false, "Map containers with published ports to 127.0.0.1")
+	hostsCmd.Flags().StringVar(&hostsFormat, "format", "hosts", "Output format: hosts or dnsmasq")
+	rootCmd.AddCommand(hostsCmd)
+}
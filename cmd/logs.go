@@ -1,33 +1,127 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/khueue/ifrit/internal/docker"
 	"github.com/khueue/ifrit/internal/ui"
 	"github.com/khueue/ifrit/internal/ui/logsviewer"
+	"github.com/khueue/ifrit/internal/ui/picker"
 	"github.com/spf13/cobra"
 )
 
 var (
-	logsFollow bool
-	logsTail   string
-	logsNoTUI  bool
+	logsFollow     bool
+	logsTail       string
+	logsSince      string
+	logsUntil      string
+	logsTimestamps bool
+	logsNoTUI      bool
+	logsPick       bool
+	logsGrep       []string
+	logsExclude    []string
+	logsOutputDir  string
 )
 
+// logsOptions returns the logs to show, as selected by the flags.
+func logsOptions() docker.LogsOptions {
+	return docker.LogsOptions{
+		Follow:     logsFollow,
+		Tail:       logsTail,
+		Since:      logsSince,
+		Until:      logsUntil,
+		Timestamps: logsTimestamps,
+	}
+}
+
+// filterWriter writes only the complete lines that pass a filter to out.
+type filterWriter struct {
+	out    io.Writer
+	filter logsviewer.Filter
+	buf    []byte
+}
+
+func (w *filterWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		if w.filter.Match(string(w.buf[:i])) {
+			if _, err := w.out.Write(w.buf[:i+1]); err != nil {
+				return 0, err
+			}
+		}
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes any trailing partial line that passes the filter.
+func (w *filterWriter) Flush() {
+	if len(w.buf) > 0 && w.filter.Match(string(w.buf)) {
+		fmt.Fprintf(w.out, "%s\n", w.buf)
+	}
+	w.buf = nil
+}
+
+// logsOutput returns where plain logs are written: stdout, filtered if
+// --grep or --exclude is set.
+func logsOutput(filter logsviewer.Filter) (io.Writer, func()) {
+	if filter.Empty() {
+		return os.Stdout, func() {}
+	}
+	w := &filterWriter{out: os.Stdout, filter: filter}
+	return w, w.Flush
+}
+
 var logsCmd = &cobra.Command{
 	Use:   "logs [project...]",
 	Short: "View logs for one or more projects",
 	Long: `Display logs from Docker Compose projects.
 
 By default, launches an interactive TUI with one tab per service across all
-projects, tailing logs in real time. Use --no-tui to fall back to plain output.`,
+projects, tailing logs in real time, plus an "All" tab interleaving every
+service in arrival order. Tabs show the state of their container, re-attach
+when it restarts, and are added for services that start later. Use --no-tui
+to fall back to plain output.
+
+Use --pick to choose a single service with a fuzzy picker instead.
+
+Use --grep and --exclude to only show lines matching (or not matching) a
+regular expression, in both modes. Both are repeatable. Patterns are
+case-insensitive unless they contain upper case. In the TUI, press f to change
+the filter.
+
+Use --since and --until to limit the logs to a time range, given as a
+timestamp or a relative time like 42m, and --timestamps to show when each line
+was logged. In the TUI, press t to toggle timestamps and w to wrap long lines
+(or scroll them with < and >).
+
+Use --output-dir to write one file per service (named <project>-<service>.log)
+instead of printing, e.g. to attach to a bug report. It implies --no-tui.`,
 	Example: `  # Interactive TUI with all projects (default)
   ifrit logs
 
   # Interactive TUI with specific projects
   ifrit logs backend frontend
 
+  # Pick a single service to view
+  ifrit logs --pick
+
   # Plain output (no TUI)
   ifrit logs --no-tui backend
 
@@ -35,8 +129,30 @@ projects, tailing logs in real time. Use --no-tui to fall back to plain output.`
   ifrit logs --no-tui -f backend
 
   # Plain output, show last 100 lines
-  ifrit logs --no-tui --tail 100 backend`,
+  ifrit logs --no-tui --tail 100 backend
+
+  # Only errors, without health checks
+  ifrit logs --grep ERROR --exclude healthz
+
+  # Logs of the last hour, up to 10 minutes ago, with timestamps
+  ifrit logs --since 1h --until 10m --timestamps backend
+
+  # Write the last 1000 lines of each backend service to ./logs
+  ifrit logs --output-dir ./logs --tail 1000 backend`,
+	ValidArgsFunction: completeProjects,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		filter, err := logsviewer.NewFilter(logsGrep, logsExclude)
+		if err != nil {
+			return err
+		}
+		if logsOutputDir != "" {
+			logsNoTUI = true
+		}
+
+		if logsPick {
+			return runPickedLogs(strings.Join(args, " "), filter)
+		}
+
 		projects := args
 		if len(projects) == 0 {
 			projects = cfg.GetProjects()
@@ -46,10 +162,13 @@ projects, tailing logs in real time. Use --no-tui to fall back to plain output.`
 			return nil
 		}
 
+		if logsOutputDir != "" {
+			return runLogsToDir(projects, filter)
+		}
 		if logsNoTUI {
-			return runPlainLogs(projects)
+			return runPlainLogs(projects, filter)
 		}
-		return runInteractiveLogs(projects)
+		return runInteractiveLogs(projects, filter)
 	},
 }
 
@@ -61,14 +180,32 @@ type serviceTab struct {
 	serviceName string
 }
 
-func runInteractiveLogs(projects []string) error {
-	tail := logsTail
-	if tail == "all" {
-		// For the TUI, default to a reasonable number of lines so startup
-		// is fast. Users can override with --tail.
-		tail = "100"
+// runPickedLogs lets the user pick a single service and shows its logs.
+func runPickedLogs(query string, filter logsviewer.Filter) error {
+	projectName, serviceName, err := pickService(query)
+	if errors.Is(err, picker.ErrCancelled) {
+		return nil
+	}
+	if err != nil {
+		return err
 	}
 
+	if logsOutputDir != "" {
+		return writeLogsToDir([]serviceTab{{projectName: projectName, serviceName: serviceName}}, filter)
+	}
+	if logsNoTUI {
+		out, flush := logsOutput(filter)
+		defer flush()
+		return manager.ComposeLogs(projectName, logsOptions(), out, serviceName)
+	}
+	return runLogsViewer([]serviceTab{{
+		label:       projectName + "/" + serviceName,
+		projectName: projectName,
+		serviceName: serviceName,
+	}}, filter, false)
+}
+
+func runInteractiveLogs(projects []string, filter logsviewer.Filter) error {
 	// Expand each project into one tab per service.
 	var tabs []serviceTab
 	for _, projectName := range projects {
@@ -90,25 +227,136 @@ func runInteractiveLogs(projects []string) error {
 		return nil
 	}
 
-	// Build TabInfo slices for the logsviewer and a lookup map so the
-	// command builder can map each tab name back to the right project/service.
+	return runLogsViewer(tabs, filter, true)
+}
+
+// runLogsViewer launches the interactive TUI with one tab per service. With
+// addNew set, services of the same projects that start later get a tab too.
+func runLogsViewer(tabs []serviceTab, filter logsviewer.Filter, addNew bool) error {
+	opts := logsOptions()
+	if opts.Tail == "all" {
+		// For the TUI, default to a reasonable number of lines so startup
+		// is fast. Users can override with --tail.
+		opts.Tail = "100"
+	}
+	// The viewer moves timestamps to a gutter, shown with --timestamps.
+	opts.Timestamps = true
+
 	tabInfos := make([]logsviewer.TabInfo, len(tabs))
-	tabLookup := make(map[string]serviceTab, len(tabs))
 	for i, t := range tabs {
 		tabInfos[i] = logsviewer.TabInfo{
 			Name:  t.label,
 			Group: t.projectName,
 		}
-		tabLookup[t.label] = t
 	}
 
-	return logsviewer.Run(tabInfos, func(tabName string) (*exec.Cmd, error) {
-		t := tabLookup[tabName]
-		return manager.ComposeServiceLogsCmd(t.projectName, t.serviceName, tail)
+	// Commands run for the viewer, e.g. on container events, would be logged
+	// over it under --verbose.
+	prev := manager.SetCommandLog(io.Discard)
+	defer manager.SetCommandLog(prev)
+
+	return logsviewer.Run(tabInfos, logsBackend{opts: opts}, logsviewer.Options{
+		Filter:     filter,
+		Timestamps: logsTimestamps,
+		Watch:      watchTabs(tabs, addNew),
 	})
 }
 
-func runPlainLogs(projects []string) error {
+// logsBackend lets the logs viewer follow and act on services, where tab
+// names are "project/service", also for tabs added later on.
+type logsBackend struct {
+	opts docker.LogsOptions
+}
+
+// splitTab returns the project and service of a tab.
+func splitTab(tabName string) (string, string) {
+	i := strings.LastIndex(tabName, "/")
+	return tabName[:i], tabName[i+1:]
+}
+
+func (b logsBackend) LogsCmd(tabName string, since time.Time) (*exec.Cmd, error) {
+	projectName, serviceName := splitTab(tabName)
+	opts := b.opts
+	if !since.IsZero() {
+		// Re-attaching: everything since the previous logs stopped.
+		opts.Tail = "all"
+		opts.Since = since.Format(time.RFC3339Nano)
+	}
+	return manager.ComposeServiceLogsCmd(projectName, serviceName, opts)
+}
+
+func (b logsBackend) Restart(tabName string) error {
+	projectName, serviceName := splitTab(tabName)
+	return manager.ComposeServiceAction(projectName, serviceName, docker.ServiceRestart)
+}
+
+func (b logsBackend) Stop(tabName string) error {
+	projectName, serviceName := splitTab(tabName)
+	return manager.ComposeServiceAction(projectName, serviceName, docker.ServiceStop)
+}
+
+func (b logsBackend) Start(tabName string) error {
+	projectName, serviceName := splitTab(tabName)
+	return manager.ComposeServiceAction(projectName, serviceName, docker.ServiceStart)
+}
+
+// ShellCmd opens the same shell as "ifrit shell <project> <service>".
+func (b logsBackend) ShellCmd(tabName string) (*exec.Cmd, error) {
+	projectName, serviceName := splitTab(tabName)
+	opts := serviceExecOptions(projectName, serviceName)
+	opts.Interactive = true
+	return manager.ComposeShellCmd(projectName, serviceName, cfg.Projects[projectName].ShellFor(serviceName), opts)
+}
+
+// watchTabs returns a logsviewer Options.Watch function that keeps the state
+// of the services shown in tabs up to date from Docker events. With addNew
+// set, it also reports the other services of their projects, which the
+// viewer adds as new tabs.
+func watchTabs(tabs []serviceTab, addNew bool) func(context.Context, func(logsviewer.TabUpdate)) error {
+	services := make(map[string][]string)
+	for _, t := range tabs {
+		services[t.projectName] = append(services[t.projectName], t.serviceName)
+	}
+
+	return func(ctx context.Context, update func(logsviewer.TabUpdate)) error {
+		// refresh reports the state of every service of a project, where
+		// services without a container are "not created".
+		refresh := func(projectName string, extra ...string) {
+			states, err := manager.ServiceStates(projectName)
+			if err != nil {
+				return
+			}
+			for _, svc := range slices.Concat(services[projectName], extra) {
+				if _, ok := states[svc]; !ok {
+					states[svc] = "not created"
+				}
+			}
+			for _, svc := range slices.Sorted(maps.Keys(states)) {
+				if addNew || slices.Contains(services[projectName], svc) {
+					update(logsviewer.TabUpdate{
+						Name:  projectName + "/" + svc,
+						Group: projectName,
+						State: states[svc],
+					})
+				}
+			}
+		}
+
+		for _, projectName := range slices.Sorted(maps.Keys(services)) {
+			refresh(projectName)
+		}
+		return manager.WatchContainers(ctx, func(e docker.ContainerEvent) {
+			if _, ok := services[e.Project]; ok {
+				refresh(e.Project, e.Service)
+			}
+		})
+	}
+}
+
+func runPlainLogs(projects []string, filter logsviewer.Filter) error {
+	out, flush := logsOutput(filter)
+	defer flush()
+
 	for i, projectName := range projects {
 		if len(projects) > 1 {
 			if i > 0 {
@@ -116,7 +364,7 @@ func runPlainLogs(projects []string) error {
 			}
 			ui.Printf("=== Logs: %s ===\n", projectName)
 		}
-		if err := manager.ComposeLogs(projectName, logsFollow, logsTail); err != nil {
+		if err := manager.ComposeLogs(projectName, logsOptions(), out); err != nil {
 			if len(projects) > 1 {
 				ui.Printf("Error: %v\n", err)
 				continue
@@ -127,9 +375,73 @@ func runPlainLogs(projects []string) error {
 	return nil
 }
 
+// runLogsToDir writes the logs of every service of the projects to one file
+// per service in --output-dir.
+func runLogsToDir(projects []string, filter logsviewer.Filter) error {
+	var services []serviceTab
+	for _, projectName := range projects {
+		names, err := manager.ComposeServices(projectName)
+		if err != nil {
+			return fmt.Errorf("failed to list services for %s: %w", projectName, err)
+		}
+		for _, svc := range names {
+			services = append(services, serviceTab{projectName: projectName, serviceName: svc})
+		}
+	}
+	if len(services) == 0 {
+		ui.Println("No services found.")
+		return nil
+	}
+	return writeLogsToDir(services, filter)
+}
+
+// writeLogsToDir writes the logs of each service to its own file in
+// --output-dir, all at once so that --follow works.
+func writeLogsToDir(services []serviceTab, filter logsviewer.Filter) error {
+	if err := os.MkdirAll(logsOutputDir, 0o755); err != nil {
+		return err
+	}
+	if logsFollow {
+		ui.Printf("Writing logs to %s (Ctrl-C to stop)\n", logsOutputDir)
+	}
+
+	errs := make([]error, len(services))
+	var wg sync.WaitGroup
+	for i, s := range services {
+		wg.Go(func() {
+			path := filepath.Join(logsOutputDir, s.projectName+"-"+s.serviceName+".log")
+			f, err := os.Create(path)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			defer f.Close()
+
+			var out io.Writer = f
+			if !filter.Empty() {
+				w := &filterWriter{out: f, filter: filter}
+				defer w.Flush()
+				out = w
+			}
+			if errs[i] = manager.ComposeLogs(s.projectName, logsOptions(), out, s.serviceName); errs[i] == nil {
+				ui.Printf("Wrote %s\n", path)
+			}
+		})
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
 func init() {
 	logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "Follow log output (only with --no-tui)")
 	logsCmd.Flags().StringVar(&logsTail, "tail", "all", "Number of lines to show from the end of the logs")
+	logsCmd.Flags().StringVar(&logsSince, "since", "", "Show logs since a timestamp (e.g. 2024-01-02T13:23:37Z) or relative time (e.g. 42m)")
+	logsCmd.Flags().StringVar(&logsUntil, "until", "", "Show logs before a timestamp or relative time")
+	logsCmd.Flags().BoolVarP(&logsTimestamps, "timestamps", "t", false, "Show timestamps (toggle with t in the TUI)")
 	logsCmd.Flags().BoolVar(&logsNoTUI, "no-tui", false, "Disable interactive TUI, print logs to stdout")
+	logsCmd.Flags().BoolVarP(&logsPick, "pick", "p", false, "Pick a single service with a fuzzy picker")
+	logsCmd.Flags().StringArrayVar(&logsGrep, "grep", nil, "Only show lines matching this regular expression (repeatable)")
+	logsCmd.Flags().StringArrayVar(&logsExclude, "exclude", nil, "Hide lines matching this regular expression (repeatable)")
+	logsCmd.Flags().StringVar(&logsOutputDir, "output-dir", "", "Write one log file per service to this directory (implies --no-tui)")
 	rootCmd.AddCommand(logsCmd)
 }
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/khueue/ifrit/internal/ui/picker"
+)
+
+// pickService opens the fuzzy picker over every project service, showing
+// whether it is running, and returns the chosen project and service. The
+// query pre-fills the search, e.g. with a project name given on the command
+// line. It returns picker.ErrCancelled if the user quits without choosing.
+func pickService(query string) (string, string, error) {
+	var items []picker.Item
+	var targets [][2]string
+
+	for _, projectName := range cfg.GetProjects() {
+		services, err := manager.ComposeServices(projectName)
+		if err != nil {
+			return "", "", err
+		}
+
+		states, err := manager.ServiceStates(projectName)
+		if err != nil {
+			states = nil
+		}
+
+		for _, svc := range services {
+			detail := "not created"
+			if states == nil {
+				detail = "unknown"
+			} else if state, ok := states[svc]; ok {
+				detail = state
+			}
+			items = append(items, picker.Item{Title: projectName + "/" + svc, Detail: detail})
+			targets = append(targets, [2]string{projectName, svc})
+		}
+	}
+
+	if len(items) == 0 {
+		return "", "", fmt.Errorf("no services found")
+	}
+
+	i, err := picker.Run("service>", items, query)
+	if err != nil {
+		return "", "", err
+	}
+	return targets[i][0], targets[i][1], nil
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/khueue/ifrit/internal/docker"
+	"github.com/khueue/ifrit/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var portsOutput string
+
+// httpPorts are container ports that are assumed to speak HTTP(S), so that
+// a clickable URL can be shown for them.
+var httpPorts = map[int]string{
+	80: "http", 443: "https", 3000: "http", 4200: "http", 5000: "http",
+	5173: "http", 8000: "http", 8008: "http", 8080: "http", 8081: "http",
+	8443: "https", 8888: "http", 9000: "http", 9090: "http",
+}
+
+// portEntry is a published port of a running container.
+type portEntry struct {
+	Project       string `json:"project"`
+	Service       string `json:"service"`
+	Container     string `json:"container"`
+	ContainerPort int    `json:"container_port"`
+	HostIP        string `json:"host_ip"`
+	HostPort      int    `json:"host_port"`
+	Protocol      string `json:"protocol"`
+	URL           string `json:"url,omitempty"`
+}
+
+// routeEntry is a route served by "ifrit proxy".
+type routeEntry struct {
+	URL     string `json:"url"`
+	Backend string `json:"backend"`
+}
+
+// collectPorts lists the published ports of all running containers, in
+// project order. IPv4 and IPv6 bindings of the same port are listed once.
+// The containers are those listed by manager.ProjectContainers.
+func collectPorts(projectContainers map[string][]docker.Container) []portEntry {
+	entries := []portEntry{}
+	for _, projectName := range cfg.GetProjects() {
+		for _, c := range projectContainers[projectName] {
+			if c.State != "running" {
+				continue
+			}
+			for _, p := range c.Publishers {
+				if p.PublishedPort == 0 {
+					continue
+				}
+				e := portEntry{
+					Project:       projectName,
+					Service:       c.Service,
+					Container:     c.Name,
+					ContainerPort: p.TargetPort,
+					HostIP:        p.URL,
+					HostPort:      p.PublishedPort,
+					Protocol:      p.Protocol,
+				}
+				if scheme, ok := httpPorts[p.TargetPort]; ok && p.Protocol == "tcp" {
+					e.URL = fmt.Sprintf("%s://localhost:%d", scheme, p.PublishedPort)
+				}
+
+				if slices.ContainsFunc(entries, func(o portEntry) bool {
+					return o.Container == e.Container && o.ContainerPort == e.ContainerPort &&
+						o.HostPort == e.HostPort && o.Protocol == e.Protocol
+				}) {
+					continue
+				}
+				entries = append(entries, e)
+			}
+		}
+	}
+	return entries
+}
+
+// collectRoutes lists the routes "ifrit proxy" would serve right now.
+func collectRoutes(projectContainers map[string][]docker.Container) ([]routeEntry, error) {
+	routes, err := manager.ProxyRoutesFor(projectContainers)
+	if err != nil {
+		return nil, err
+	}
+
+	_, port, _ := net.SplitHostPort(cfg.Proxy.Listen)
+	entries := []routeEntry{}
+	for _, name := range slices.Sorted(maps.Keys(routes)) {
+		entries = append(entries, routeEntry{
+			URL:     fmt.Sprintf("http://%s.%s:%s", name, cfg.Proxy.Domain, port),
+			Backend: routes[name].Host,
+		})
+	}
+	return entries, nil
+}
+
+var portsCmd = &cobra.Command{
+	Use:   "ports",
+	Short: "List published ports and proxy routes of running services",
+	Long: `List every published host port across all running projects, with the
+project, service, container port and protocol. Ports that look like HTTP get a
+clickable URL.
+
+Routes served by 'ifrit proxy' (from ifrit.yml and service labels) are listed
+as well. Use --output json for tooling.`,
+	Example: `  # Show all endpoints
+  ifrit ports
+
+  # Machine-readable output
+  ifrit ports --output json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if portsOutput != "table" && portsOutput != "json" {
+			return fmt.Errorf("invalid output format %q, expected table or json", portsOutput)
+		}
+
+		containers := manager.ProjectContainers()
+		ports := collectPorts(containers)
+		routes, err := collectRoutes(containers)
+		if err != nil {
+			return err
+		}
+
+		if portsOutput == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(struct {
+				Ports  []portEntry  `json:"ports"`
+				Routes []routeEntry `json:"routes"`
+			}{ports, routes})
+		}
+
+		if len(ports) == 0 {
+			ui.Println("No published ports.")
+		} else {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "PROJECT\tSERVICE\tCONTAINER PORT\tHOST\tURL")
+			for _, p := range ports {
+				host := net.JoinHostPort(p.HostIP, strconv.Itoa(p.HostPort))
+				fmt.Fprintf(w, "%s\t%s\t%d/%s\t%s\t%s\n", p.Project, p.Service, p.ContainerPort, p.Protocol, host, p.URL)
+			}
+			w.Flush()
+		}
+
+		if len(routes) > 0 {
+			ui.Println()
+			ui.Println("Proxy routes (ifrit proxy):")
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "URL\tBACKEND")
+			for _, r := range routes {
+				fmt.Fprintf(w, "%s\t%s\n", r.URL, r.Backend)
+			}
+			w.Flush()
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	portsCmd.Flags().StringVarP(&portsOutput, "output", "o", "table", "Output format: table or json")
+	rootCmd.AddCommand(portsCmd)
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/khueue/ifrit/internal/config"
+	"github.com/khueue/ifrit/internal/proxy"
+	"github.com/khueue/ifrit/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	proxyListen string
+	proxyDomain string
+)
+
+// proxyRefreshInterval is how long the route table is reused before asking
+// Docker again.
+const proxyRefreshInterval = 5 * time.Second
+
+var proxyCmd = &cobra.Command{
+	Use:   "proxy",
+	Short: "Run a local HTTP reverse proxy with hostname routing",
+	Long: `Run an HTTP reverse proxy on the host that routes
+http://<name>.<domain>:<port> to service ports, so projects don't need to
+juggle host ports.
+
+Routes come from the proxy.routes section of ifrit.yml, and from compose
+services labeled with ifrit.proxy.port (and optionally ifrit.proxy.host).
+Names default to <service>.<project>, e.g. http://api.backend.localhost:8000.
+Browsers resolve *.localhost to the loopback address without any DNS setup.
+
+Published ports are reached through the host; other ports through the
+container's IP on the shared network, which works on Linux but not with
+Docker Desktop. Routes are refreshed every few seconds, so services can be
+started and stopped while the proxy runs. Visit the bare domain for a list of
+routes.`,
+	Example: `  # Start the proxy with the configured (or default) address and domain
+  ifrit proxy
+
+  # Listen on port 80 (may require privileges)
+  ifrit proxy --listen 127.0.0.1:80`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		listen := cfg.Proxy.Listen
+		if cmd.Flags().Changed("listen") {
+			listen = proxyListen
+		}
+		domain := cfg.Proxy.Domain
+		if cmd.Flags().Changed("domain") {
+			domain = proxyDomain
+		}
+
+		table := &proxy.Table{
+			Load: func(ctx context.Context) (map[string]*url.URL, error) {
+				return manager.ProxyRoutes()
+			},
+			TTL: proxyRefreshInterval,
+		}
+
+		names, err := table.Names(context.Background())
+		if err != nil {
+			return err
+		}
+
+		_, port, err := net.SplitHostPort(listen)
+		if err != nil {
+			return fmt.Errorf("invalid listen address %q: %w", listen, err)
+		}
+		ui.Printf("Proxy listening on %s\n", listen)
+		if len(names) == 0 {
+			ui.Println("No routes yet; start services or add proxy routes to ifrit.yml.")
+		}
+		for _, name := range names {
+			ui.Printf("  http://%s.%s:%s/\n", name, domain, port)
+		}
+
+		server := &http.Server{
+			Addr:              listen,
+			Handler:           proxy.New(domain, table),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = server.Shutdown(shutdownCtx)
+		}()
+
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("proxy failed: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	proxyCmd.Flags().StringVar(&proxyListen, "listen", "", "Address to listen on (overrides proxy.listen, default "+config.DefaultProxyListen+")")
+	proxyCmd.Flags().StringVar(&proxyDomain, "domain", "", "Domain to route under (overrides proxy.domain, default "+config.DefaultProxyDomain+")")
+	rootCmd.AddCommand(proxyCmd)
+}
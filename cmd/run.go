@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os/exec"
+	"slices"
+
+	"github.com/khueue/ifrit/internal/docker"
+	"github.com/khueue/ifrit/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var runList bool
+
+func printTaskList() {
+	tasks := cfg.GetTasks()
+	if len(tasks) == 0 {
+		ui.Println("No tasks defined.")
+		return
+	}
+
+	width := 0
+	for _, name := range tasks {
+		width = max(width, len(name))
+	}
+
+	ui.Println("Available tasks:")
+	for _, name := range tasks {
+		task := cfg.Tasks[name]
+		where := "host"
+		if task.Service != "" {
+			where = task.Project + "/" + task.Service
+		} else if task.Project != "" {
+			where = "host:" + task.Project
+		}
+		ui.Printf("  %-*s  %s (%s)\n", width, name, task.Description, where)
+	}
+}
+
+var runCmd = &cobra.Command{
+	Use:   "run <task> [args...]",
+	Short: "Run a named task from ifrit.yml",
+	Long: `Run a task defined in the tasks section of ifrit.yml.
+
+A task runs inside a project service, or on the host (in the project directory
+if a project is set). Tasks listed in depends_on are run first, each at most
+once. Any extra arguments are passed to the task's command as "$@".`,
+	Example: `  # List available tasks
+  ifrit run --list
+
+  # Run database migrations
+  ifrit run migrate
+
+  # Pass extra arguments to the task
+  ifrit run test backend`,
+	ValidArgsFunction: completeTasks,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if runList || len(args) == 0 {
+			printTaskList()
+			if !runList {
+				return fmt.Errorf("requires a task name")
+			}
+			return nil
+		}
+
+		taskName, taskArgs := args[0], args[1:]
+		order, err := cfg.TaskOrder(taskName)
+		if err != nil {
+			return err
+		}
+
+		interactive := isTerminal()
+		for _, name := range order {
+			task := cfg.Tasks[name]
+
+			// Only the requested task receives the extra arguments.
+			command := []string{"sh", "-c", task.Command, name}
+			if name == taskName {
+				command = append(command, taskArgs...)
+			}
+
+			var env []string
+			for _, k := range slices.Sorted(maps.Keys(task.Env)) {
+				env = append(env, k+"="+task.Env[k])
+			}
+
+			if len(order) > 1 {
+				ui.Printf("Running task: %s\n", name)
+			}
+
+			if task.Service != "" {
+				opts := docker.ExecOptions{
+					Interactive: interactive,
+					Env:         env,
+					Mode:        docker.ExecMode(cfg.ExecMode),
+				}
+				err = manager.ComposeExec(task.Project, task.Service, command, opts)
+			} else {
+				err = manager.HostExec(task.Project, command, env)
+			}
+			if err != nil {
+				if exitErr, ok := errors.AsType[*exec.ExitError](err); ok {
+					if name != taskName {
+						ui.Printf("Task %s failed with exit status %d\n", name, exitErr.ExitCode())
+					}
+					return &SilentExitError{Code: exitErr.ExitCode()}
+				}
+				return err
+			}
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	runCmd.Flags().BoolVarP(&runList, "list", "l", false, "List available tasks with their descriptions")
+	runCmd.Flags().SetInterspersed(false)
+	rootCmd.AddCommand(runCmd)
+}
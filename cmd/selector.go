@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// target identifies a single service within a project.
+type target struct {
+	project string
+	service string
+}
+
+func (t target) String() string {
+	return t.project + "/" + t.service
+}
+
+// matchTargets expands selectors into the services they match, in project
+// and service order, each at most once. A selector is a "project/service"
+// glob pattern (e.g. "*/api", "backend/*"), or a bare project pattern that
+// matches all of that project's services.
+func matchTargets(selectors []string) ([]target, error) {
+	type pattern struct{ project, service string }
+
+	var patterns []pattern
+	for _, sel := range selectors {
+		projectPat, servicePat, ok := strings.Cut(sel, "/")
+		if !ok {
+			servicePat = "*"
+		}
+		for _, p := range []string{projectPat, servicePat} {
+			if _, err := path.Match(p, ""); err != nil {
+				return nil, fmt.Errorf("invalid selector %q: %w", sel, err)
+			}
+		}
+		patterns = append(patterns, pattern{projectPat, servicePat})
+	}
+
+	var targets []target
+	for _, projectName := range cfg.GetProjects() {
+		var servicePats []string
+		for _, p := range patterns {
+			if ok, _ := path.Match(p.project, projectName); ok {
+				servicePats = append(servicePats, p.service)
+			}
+		}
+		if len(servicePats) == 0 {
+			continue
+		}
+
+		services, err := manager.ComposeServices(projectName)
+		if err != nil {
+			return nil, err
+		}
+		for _, svc := range services {
+			for _, p := range servicePats {
+				if ok, _ := path.Match(p, svc); ok {
+					targets = append(targets, target{projectName, svc})
+					break
+				}
+			}
+		}
+	}
+
+	return targets, nil
+}
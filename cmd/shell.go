@@ -3,22 +3,48 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"os/exec"
 	"slices"
 	"strings"
 
+	"github.com/khueue/ifrit/internal/docker"
 	"github.com/khueue/ifrit/internal/ui"
+	"github.com/khueue/ifrit/internal/ui/picker"
 	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 )
 
-var shellInteractive bool
+var (
+	shellInteractive bool
+	shellUser        string
+	shellWorkdir     string
+	shellEnv         []string
+	shellIndex       int
+	shellPrivileged  bool
+	shellNoStart     bool
+	shellRun         bool
+)
 
 func isTerminal() bool {
 	return isatty.IsTerminal(os.Stdin.Fd()) && isatty.IsTerminal(os.Stdout.Fd())
 }
 
+// splitAtDash splits args at "--" into ifrit arguments and a container
+// command, reporting whether there was a "--". With SetInterspersed(false),
+// pflag keeps a "--" that follows other arguments, but drops a leading one
+// and reports it through ArgsLenAtDash instead.
+func splitAtDash(cmd *cobra.Command, args []string) ([]string, []string, bool) {
+	if cmd.ArgsLenAtDash() == 0 {
+		return nil, args, true
+	}
+	if i := slices.Index(args, "--"); i >= 0 {
+		return args[:i], args[i+1:], true
+	}
+	return args, nil, false
+}
+
 func printShellUsageHint() {
 	projects := cfg.GetProjects()
 	if len(projects) == 0 {
@@ -48,21 +74,89 @@ func printShellUsageHint() {
 	ui.Println("Run 'ifrit shell --help' for full usage information.")
 }
 
+// printNoShellHint explains what to do when a container has no shell at all.
+func printNoShellHint(projectName, serviceName string) {
+	ui.Printf("Service %s/%s has no shell (bash, zsh, ash or sh).\n", projectName, serviceName)
+	ui.Println("The image is probably distroless or scratch-based. To inspect it, attach a")
+	ui.Println("debug sidecar that shares its PID and network namespaces:")
+	ui.Printf("  ifrit debug %s %s\n", projectName, serviceName)
+}
+
+// serviceExecOptions returns the exec options for a service configured in
+// ifrit.yml.
+func serviceExecOptions(projectName, serviceName string) docker.ExecOptions {
+	svc := cfg.Projects[projectName].Services[serviceName]
+	opts := docker.ExecOptions{
+		User:       svc.User,
+		Workdir:    svc.Workdir,
+		Privileged: svc.Privileged,
+		Mode:       docker.ExecMode(cfg.ExecMode),
+	}
+	for _, k := range slices.Sorted(maps.Keys(svc.Env)) {
+		opts.Env = append(opts.Env, k+"="+svc.Env[k])
+	}
+	return opts
+}
+
+// shellExecOptions builds the exec options for a service, starting from its
+// defaults in ifrit.yml and applying any flags the user set explicitly.
+func shellExecOptions(cmd *cobra.Command, projectName, serviceName string) docker.ExecOptions {
+	opts := serviceExecOptions(projectName, serviceName)
+	opts.Interactive = shellInteractive
+	opts.Index = shellIndex
+
+	flags := cmd.Flags()
+	if flags.Changed("user") {
+		opts.User = shellUser
+	}
+	if flags.Changed("workdir") {
+		opts.Workdir = shellWorkdir
+	}
+	if flags.Changed("privileged") {
+		opts.Privileged = shellPrivileged
+	}
+	if shellNoStart {
+		opts.Mode = docker.ExecNoStart
+	}
+	if shellRun {
+		opts.Mode = docker.ExecRun
+	}
+
+	// Flag values come last so that they win over configured defaults.
+	opts.Env = append(opts.Env, shellEnv...)
+
+	return opts
+}
+
 var shellCmd = &cobra.Command{
 	Use:   "shell <project> <service> [-- command [args...]]",
 	Short: "Open a shell or execute a command in a running container",
 	Long: `Open an interactive shell or execute a command in a running container.
 
-If no command is specified, opens an interactive shell (/bin/bash or /bin/sh).
-If a command is provided after '--', executes that command in the container.
+If no command is specified, opens an interactive shell: the shell configured
+for the project or service in ifrit.yml, or else the first of bash, zsh, ash
+and sh found in the container. If a command is provided after '--', executes
+that command in the container.
+
+If the project or service is omitted in a terminal, a fuzzy picker lists all
+services (with their running state) to choose from.
 
 If the service is not already running, it will be started automatically.
+Use --no-start to fail instead, or --run to use a throwaway container
+('docker compose run --rm', without dependencies). The default can be changed
+with exec_mode in ifrit.yml.
 
 By default, interactive mode is auto-detected based on whether stdin and stdout
-are connected to a terminal. Use --interactive to override this behavior.`,
+are connected to a terminal. Use --interactive to override this behavior.
+
+The --user, --workdir, --env and --privileged flags override any defaults set
+for the service under projects.<project>.services in ifrit.yml.`,
 	Example: `  # Open an interactive shell in the backend api service
   ifrit shell backend api
 
+  # Pick a service interactively
+  ifrit shell
+
   # Execute a command in the container
   ifrit shell backend api -- ls -al
 
@@ -74,7 +168,20 @@ are connected to a terminal. Use --interactive to override this behavior.`,
 
   # Force interactive mode on/off
   ifrit shell --interactive=false backend api -- env > output.txt
-  ifrit shell --interactive=true backend api -- top`,
+  ifrit shell --interactive=true backend api -- top
+
+  # Run as root in a specific directory with extra env
+  ifrit shell --user root --workdir /app -e DEBUG=1 backend api
+
+  # Enter the second replica of a scaled service
+  ifrit shell --index 2 backend worker
+
+  # Only exec if the service is already running
+  ifrit shell --no-start backend api
+
+  # Use a throwaway container instead of the running one
+  ifrit shell --run backend api -- ./manage.py check`,
+	ValidArgsFunction: completeProjectService,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// If the user didn't explicitly set --interactive, auto-detect
 		// based on whether stdin/stdout are terminals.
@@ -82,18 +189,18 @@ are connected to a terminal. Use --interactive to override this behavior.`,
 			shellInteractive = isTerminal()
 		}
 
-		// Because SetInterspersed(false) is used, cobra/pflag does not
-		// process "--" itself, so we parse it manually from the args slice.
-		dashIndex := slices.Index(args, "--")
+		positionalArgs, commandArgs, _ := splitAtDash(cmd, args)
 
-		var positionalArgs []string
-		var commandArgs []string
-
-		if dashIndex >= 0 {
-			positionalArgs = args[:dashIndex]
-			commandArgs = args[dashIndex+1:]
-		} else {
-			positionalArgs = args
+		// In a terminal, let the user pick the missing project/service.
+		if len(positionalArgs) < 2 && isTerminal() {
+			projectName, serviceName, err := pickService(strings.Join(positionalArgs, "/"))
+			if errors.Is(err, picker.ErrCancelled) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			positionalArgs = []string{projectName, serviceName}
 		}
 
 		if len(positionalArgs) < 2 {
@@ -138,20 +245,24 @@ are connected to a terminal. Use --interactive to override this behavior.`,
 			return fmt.Errorf("service %q not found in project %q", serviceName, projectName)
 		}
 
-		var command []string
+		opts := shellExecOptions(cmd, projectName, serviceName)
 
-		if dashIndex == -1 || len(commandArgs) == 0 {
-			// No "--" provided, or nothing after it; open an interactive shell.
-			command = []string{"/bin/bash"}
+		if len(commandArgs) == 0 {
+			// No "--" provided, or nothing after it; open an interactive
+			// shell, detecting one if none is configured.
+			err = manager.ComposeShell(projectName, serviceName, cfg.Projects[projectName].ShellFor(serviceName), opts)
+			if errors.Is(err, docker.ErrNoShell) {
+				printNoShellHint(projectName, serviceName)
+			}
 		} else if len(commandArgs) == 1 {
 			// Single arg: wrap with sh -c so that shell expressions like
 			// "ls && echo done" are interpreted correctly.
-			command = []string{"sh", "-c", commandArgs[0]}
+			err = manager.ComposeExec(projectName, serviceName, []string{"sh", "-c", commandArgs[0]}, opts)
 		} else {
-			command = commandArgs
+			err = manager.ComposeExec(projectName, serviceName, commandArgs, opts)
 		}
 
-		if err := manager.ComposeExec(projectName, serviceName, command, shellInteractive); err != nil {
+		if err != nil {
 			if exitErr, ok := errors.AsType[*exec.ExitError](err); ok {
 				return &SilentExitError{Code: exitErr.ExitCode()}
 			}
@@ -163,6 +274,14 @@ are connected to a terminal. Use --interactive to override this behavior.`,
 
 func init() {
 	shellCmd.Flags().BoolVarP(&shellInteractive, "interactive", "i", true, "Run in interactive mode with TTY (auto-detected by default)")
+	shellCmd.Flags().StringVarP(&shellUser, "user", "u", "", "Run as this user (name or uid[:gid])")
+	shellCmd.Flags().StringVarP(&shellWorkdir, "workdir", "w", "", "Working directory inside the container")
+	shellCmd.Flags().StringArrayVarP(&shellEnv, "env", "e", nil, "Set environment variables (KEY=VAL, repeatable)")
+	shellCmd.Flags().IntVar(&shellIndex, "index", 0, "Container index for services with multiple replicas")
+	shellCmd.Flags().BoolVar(&shellPrivileged, "privileged", false, "Give extended privileges to the process")
+	shellCmd.Flags().BoolVar(&shellNoStart, "no-start", false, "Fail if the service is not already running instead of starting it")
+	shellCmd.Flags().BoolVar(&shellRun, "run", false, "Use a throwaway container (docker compose run --rm) instead of exec")
+	shellCmd.MarkFlagsMutuallyExclusive("no-start", "run")
 	shellCmd.Flags().SetInterspersed(false)
 	rootCmd.AddCommand(shellCmd)
 }
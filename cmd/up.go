@@ -26,6 +26,7 @@ Use --recreate to also force-recreate all containers and their dependencies.`,
 
   # Force-recreate all containers from scratch
   ifrit up --recreate backend`,
+	ValidArgsFunction: completeProjects,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 || upAll {
 			if len(cfg.GetProjects()) == 0 {
@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
+	"time"
 
 	"go.yaml.in/yaml/v4"
 )
@@ -15,17 +17,109 @@ type Config struct {
 	NamePrefix         string             `yaml:"name_prefix"`
 	SharedNetwork      string             `yaml:"shared_network"`
 	ImplicitNetworking *bool              `yaml:"implicit_networking"`
+	DebugImage         string             `yaml:"debug_image,omitempty"`
+	ExecMode           string             `yaml:"exec_mode,omitempty"` // start, no-start or run
+	ForwardImage       string             `yaml:"forward_image,omitempty"`
+	Hooks              Hooks              `yaml:"hooks,omitempty"`
 	Projects           map[string]Project `yaml:"projects"`
+	Tasks              map[string]Task    `yaml:"tasks,omitempty"`
+	Proxy              Proxy              `yaml:"proxy,omitempty"`
 }
 
 // Project represents a Docker Compose subproject.
 type Project struct {
-	Path         string   `yaml:"path"`
-	ComposeFiles []string `yaml:"compose_files,omitempty"`
+	Path         string             `yaml:"path"`
+	ComposeFiles []string           `yaml:"compose_files,omitempty"`
+	Hooks        Hooks              `yaml:"hooks,omitempty"`
+	Shell        string             `yaml:"shell,omitempty"`    // default shell for "ifrit shell"
+	Services     map[string]Service `yaml:"services,omitempty"` // per-service settings
+}
+
+// Service holds ifrit settings for a single compose service. Apart from
+// Shell, these are defaults for "ifrit shell" that its flags override.
+type Service struct {
+	Shell      string            `yaml:"shell,omitempty"` // overrides the project's shell
+	User       string            `yaml:"user,omitempty"`
+	Workdir    string            `yaml:"workdir,omitempty"`
+	Env        map[string]string `yaml:"env,omitempty"`
+	Privileged bool              `yaml:"privileged,omitempty"`
+}
+
+// ShellFor returns the configured shell for a service, falling back to the
+// project's shell. It returns "" when neither is set.
+func (p Project) ShellFor(serviceName string) string {
+	if svc, ok := p.Services[serviceName]; ok && svc.Shell != "" {
+		return svc.Shell
+	}
+	return p.Shell
+}
+
+// Hooks holds the commands to run at each lifecycle point of a project.
+// Hooks defined at the top level of the config run for every project,
+// before the project's own hooks.
+type Hooks struct {
+	PreUp    []Hook `yaml:"pre_up,omitempty"`
+	PostUp   []Hook `yaml:"post_up,omitempty"`
+	PreDown  []Hook `yaml:"pre_down,omitempty"`
+	PostDown []Hook `yaml:"post_down,omitempty"`
+}
+
+// Hook is a single lifecycle command. It runs on the host in the project
+// directory, or inside the named service when Service is set.
+type Hook struct {
+	Command string        `yaml:"command"`
+	Service string        `yaml:"service,omitempty"`
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+}
+
+// RunTimeout returns the hook's timeout, or DefaultHookTimeout if it has none.
+func (h Hook) RunTimeout() time.Duration {
+	if h.Timeout == 0 {
+		return DefaultHookTimeout
+	}
+	return h.Timeout
+}
+
+// Task is a named command runnable with "ifrit run". It runs inside Service
+// when set, otherwise on the host (in the project directory if Project is
+// set). Extra arguments given on the command line are available to the
+// command as "$@".
+type Task struct {
+	Description string            `yaml:"description,omitempty"`
+	Project     string            `yaml:"project,omitempty"`
+	Service     string            `yaml:"service,omitempty"`
+	Command     string            `yaml:"command"`
+	Env         map[string]string `yaml:"env,omitempty"`
+	DependsOn   []string          `yaml:"depends_on,omitempty"`
+}
+
+// Proxy configures the "ifrit proxy" reverse proxy.
+type Proxy struct {
+	Listen string  `yaml:"listen,omitempty"` // host:port, defaults to DefaultProxyListen
+	Domain string  `yaml:"domain,omitempty"` // defaults to DefaultProxyDomain
+	Routes []Route `yaml:"routes,omitempty"`
+}
+
+// Route maps http://<host>.<domain> to a port of a project service. Host
+// defaults to "<service>.<project>".
+type Route struct {
+	Host    string `yaml:"host,omitempty"`
+	Project string `yaml:"project"`
+	Service string `yaml:"service"`
+	Port    int    `yaml:"port"`
 }
 
 const ConfigFileName = "ifrit.yml"
 
+// Proxy defaults.
+const (
+	DefaultProxyListen = "127.0.0.1:8000"
+	DefaultProxyDomain = "localhost"
+)
+
+// DefaultHookTimeout is used for hooks that don't specify a timeout.
+const DefaultHookTimeout = 5 * time.Minute
+
 // Load reads and parses the ifrit.yml configuration file.
 func Load(configPath string) (*Config, error) {
 	if configPath == "" {
@@ -73,11 +167,25 @@ func Load(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("implicit_networking is required in config")
 	}
 
+	switch cfg.ExecMode {
+	case "", "start", "no-start", "run":
+	default:
+		return nil, fmt.Errorf("exec_mode must be one of start, no-start or run, got %q", cfg.ExecMode)
+	}
+
+	if err := cfg.Hooks.validate("hooks", false); err != nil {
+		return nil, err
+	}
+
 	for name, project := range cfg.Projects {
 		if len(project.ComposeFiles) == 0 {
 			project.ComposeFiles = []string{"compose.yml"}
 		}
 
+		if err := project.Hooks.validate(fmt.Sprintf("projects.%s.hooks", name), true); err != nil {
+			return nil, err
+		}
+
 		if project.Path != "" && !filepath.IsAbs(project.Path) {
 			project.Path = filepath.Join(wd, project.Path)
 		}
@@ -85,9 +193,71 @@ func Load(configPath string) (*Config, error) {
 		cfg.Projects[name] = project
 	}
 
+	if cfg.Proxy.Listen == "" {
+		cfg.Proxy.Listen = DefaultProxyListen
+	}
+	if cfg.Proxy.Domain == "" {
+		cfg.Proxy.Domain = DefaultProxyDomain
+	}
+	for i, route := range cfg.Proxy.Routes {
+		if _, ok := cfg.Projects[route.Project]; !ok {
+			return nil, fmt.Errorf("proxy.routes[%d]: project %q not found in config", i, route.Project)
+		}
+		if route.Service == "" {
+			return nil, fmt.Errorf("proxy.routes[%d]: service is required", i)
+		}
+		if route.Port <= 0 {
+			return nil, fmt.Errorf("proxy.routes[%d]: port is required", i)
+		}
+		if route.Host == "" {
+			cfg.Proxy.Routes[i].Host = route.Service + "." + route.Project
+		}
+	}
+
+	for name, task := range cfg.Tasks {
+		if task.Command == "" {
+			return nil, fmt.Errorf("tasks.%s: command is required", name)
+		}
+		if task.Service != "" && task.Project == "" {
+			return nil, fmt.Errorf("tasks.%s: service requires a project", name)
+		}
+		if _, ok := cfg.Projects[task.Project]; task.Project != "" && !ok {
+			return nil, fmt.Errorf("tasks.%s: project %s not found in config", name, task.Project)
+		}
+		if _, err := cfg.TaskOrder(name); err != nil {
+			return nil, err
+		}
+	}
+
 	return &cfg, nil
 }
 
+// validate checks every hook. Service hooks are only allowed on projects,
+// since top-level hooks apply to all of them. Default timeouts are applied
+// when hooks run, so that saving the config doesn't write them out.
+func (h *Hooks) validate(path string, allowService bool) error {
+	stages := map[string][]Hook{
+		"pre_up":    h.PreUp,
+		"post_up":   h.PostUp,
+		"pre_down":  h.PreDown,
+		"post_down": h.PostDown,
+	}
+	for stage, hooks := range stages {
+		for i, hook := range hooks {
+			if hook.Command == "" {
+				return fmt.Errorf("%s.%s[%d]: command is required", path, stage, i)
+			}
+			if hook.Service != "" && !allowService {
+				return fmt.Errorf("%s.%s[%d]: service is only allowed in project hooks", path, stage, i)
+			}
+			if hook.Timeout < 0 {
+				return fmt.Errorf("%s.%s[%d]: timeout must not be negative", path, stage, i)
+			}
+		}
+	}
+	return nil
+}
+
 // Save writes the configuration to a file.
 func (c *Config) Save(configPath string) error {
 	if configPath == "" {
@@ -110,3 +280,51 @@ func (c *Config) Save(configPath string) error {
 func (c *Config) GetProjects() []string {
 	return slices.Sorted(maps.Keys(c.Projects))
 }
+
+// GetTasks returns a sorted list of all task names.
+func (c *Config) GetTasks() []string {
+	return slices.Sorted(maps.Keys(c.Tasks))
+}
+
+// TaskOrder returns the tasks to run for the named task, with dependencies
+// first and each task listed once. It fails on unknown tasks and cycles.
+func (c *Config) TaskOrder(name string) ([]string, error) {
+	var order []string
+	done := map[string]bool{}
+	visiting := map[string]bool{}
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		if done[name] {
+			return nil
+		}
+		path = append(path, name)
+		if visiting[name] {
+			return fmt.Errorf("task dependency cycle: %s", strings.Join(path, " -> "))
+		}
+		task, ok := c.Tasks[name]
+		if !ok {
+			if len(path) > 1 {
+				return fmt.Errorf("task %s (required by %s) not found in config", name, path[len(path)-2])
+			}
+			return fmt.Errorf("task %s not found in config", name)
+		}
+
+		visiting[name] = true
+		for _, dep := range task.DependsOn {
+			if err := visit(dep, path); err != nil {
+				return err
+			}
+		}
+		visiting[name] = false
+
+		done[name] = true
+		order = append(order, name)
+		return nil
+	}
+
+	if err := visit(name, nil); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
@@ -0,0 +1,93 @@
+package docker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/khueue/ifrit/internal/config"
+	"github.com/khueue/ifrit/internal/state"
+)
+
+// servicesCacheKey hashes every input that can change the output of
+// "docker compose config --services" for a project: the compose args, the
+// contents of every compose file (including the networking override), the
+// project's .env file and the COMPOSE_*/IFRIT_* environment. Files pulled in
+// indirectly (include, extends) are not tracked.
+func (m *Manager) servicesCacheKey(project config.Project, baseArgs []string) (string, error) {
+	h := sha256.New()
+	for _, arg := range baseArgs {
+		fmt.Fprintf(h, "arg:%s\x00", arg)
+	}
+
+	files := []string{filepath.Join(project.Path, ".env")}
+	for i, arg := range baseArgs {
+		if arg == "--file" && i+1 < len(baseArgs) {
+			files = append(files, baseArgs[i+1])
+		}
+	}
+	for _, path := range files {
+		if err := hashFile(h, path); err != nil {
+			return "", err
+		}
+	}
+
+	env := slices.Sorted(slices.Values(m.composeEnv()))
+	for _, e := range env {
+		if strings.HasPrefix(e, "COMPOSE_") || strings.HasPrefix(e, "IFRIT_") {
+			fmt.Fprintf(h, "env:%s\x00", e)
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashFile writes the path and contents of a file to h. Missing files are
+// hashed as such, so that creating them changes the key.
+func hashFile(h io.Writer, path string) error {
+	fmt.Fprintf(h, "file:%s\x00", path)
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		fmt.Fprint(h, "missing\x00")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(h, f)
+	return err
+}
+
+// servicesCachePath returns the cache file for a project. There is one file
+// per project, so stale entries are overwritten rather than piling up.
+func (m *Manager) servicesCachePath(project config.Project, projectName string) (string, error) {
+	sum := sha256.Sum256([]byte(m.config.NamePrefix + "\x00" + projectName + "\x00" + project.Path))
+	return state.Path("services", hex.EncodeToString(sum[:8]))
+}
+
+// readServicesCache returns the cached services for a project if the cache
+// was written with the given key.
+func readServicesCache(path, key string) ([]string, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if lines[0] != key {
+		return nil, false
+	}
+	return lines[1:], true
+}
+
+// writeServicesCache stores the services for a project under the given key.
+// Failures are ignored; the cache is only an optimization.
+func writeServicesCache(path, key string, services []string) {
+	data := strings.Join(slices.Concat([]string{key}, services), "\n") + "\n"
+	_ = os.WriteFile(path, []byte(data), 0o644)
+}
@@ -0,0 +1,126 @@
+package docker
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/khueue/ifrit/internal/ui"
+)
+
+// DefaultDebugImage is the toolbox image used by Debug unless configured.
+const DefaultDebugImage = "busybox:latest"
+
+// debugLabel marks debug sidecars so that leftovers can be found and removed.
+// Its value is the config's name prefix.
+const debugLabel = "ifrit.debug"
+
+// ContainerID returns the ID of the (first) container of a service.
+func (m *Manager) ContainerID(projectName, serviceName string) (string, error) {
+	project, err := m.getProject(projectName)
+	if err != nil {
+		return "", err
+	}
+
+	baseArgs, err := m.composeArgs(project, projectName)
+	if err != nil {
+		return "", err
+	}
+
+	args := append(baseArgs, "ps", "--quiet", serviceName)
+
+	cmd := composeCommand(args...)
+	cmd.Dir = project.Path
+	cmd.Env = m.composeEnv()
+	m.logCommand(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to find container for service %s in project %s: %w", serviceName, projectName, err)
+	}
+
+	id, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	if id == "" {
+		return "", fmt.Errorf("service %s in project %s has no container", serviceName, projectName)
+	}
+	return id, nil
+}
+
+// Debug starts a temporary toolbox container that shares the PID and network
+// namespaces of the service's container and attaches an interactive shell to
+// it. The target's filesystem is reachable via /proc/1/root, which is also the
+// shell's starting directory. The sidecar is removed when the session ends.
+func (m *Manager) Debug(projectName, serviceName, image string, tty bool) error {
+	if err := m.ensureServiceRunning(projectName, serviceName); err != nil {
+		return err
+	}
+
+	id, err := m.ContainerID(projectName, serviceName)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s_debug_%s_%s_%d", m.config.NamePrefix, projectName, serviceName, os.Getpid())
+	target := "container:" + id
+
+	args := []string{
+		"run", "--rm", "--interactive",
+		"--name", name,
+		"--pid", target,
+		"--network", target,
+		// Needed to access the target's filesystem through /proc/1/root.
+		"--cap-add", "SYS_PTRACE",
+		"--label", fmt.Sprintf("%s=%s", debugLabel, m.config.NamePrefix),
+		"--label", fmt.Sprintf("%s.target=%s/%s", debugLabel, projectName, serviceName),
+	}
+	if tty {
+		args = append(args, "--tty")
+	}
+	args = append(args, image, "sh", "-c", "cd /proc/1/root 2>/dev/null; exec sh")
+
+	ui.Printf("Attaching debug sidecar (%s) to %s/%s\n", image, projectName, serviceName)
+	ui.Println("The target's filesystem is mounted at /proc/1/root.")
+
+	cmd := exec.Command("docker", args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	m.logCommand(cmd)
+	runErr := cmd.Run()
+
+	// --rm normally takes care of this, but make sure the sidecar is gone
+	// even if the client was disconnected from it.
+	rm := exec.Command("docker", "rm", "--force", name)
+	m.logCommand(rm)
+	_ = rm.Run()
+
+	if runErr != nil {
+		return fmt.Errorf("debug session for service %s in project %s failed: %w", serviceName, projectName, runErr)
+	}
+	return nil
+}
+
+// CleanupDebug removes leftover debug sidecars created with this config's
+// name prefix, returning how many were removed.
+func (m *Manager) CleanupDebug() (int, error) {
+	cmd := exec.Command("docker", "ps", "--all", "--quiet", "--filter", fmt.Sprintf("label=%s=%s", debugLabel, m.config.NamePrefix))
+	m.logCommand(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list debug containers: %w", err)
+	}
+
+	ids := strings.Fields(string(output))
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	rm := exec.Command("docker", append([]string{"rm", "--force"}, ids...)...)
+	m.logCommand(rm)
+	rm.Stderr = os.Stderr
+	if err := rm.Run(); err != nil {
+		return 0, fmt.Errorf("failed to remove debug containers: %w", err)
+	}
+	return len(ids), nil
+}
@@ -2,12 +2,18 @@ package docker
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/khueue/ifrit/internal/config"
 	"github.com/khueue/ifrit/internal/ui"
@@ -18,19 +24,34 @@ func composeCommand(args ...string) *exec.Cmd {
 	return exec.Command("docker", slices.Concat([]string{"compose"}, args)...)
 }
 
-// Manager handles Docker Compose operations.
+// composeCommandContext is like composeCommand but kills the process when ctx
+// is done.
+func composeCommandContext(ctx context.Context, args ...string) *exec.Cmd {
+	return exec.CommandContext(ctx, "docker", slices.Concat([]string{"compose"}, args)...)
+}
+
+// Manager handles Docker Compose operations. It may be used from several
+// goroutines, e.g. by the logs viewer.
 type Manager struct {
-	config          *config.Config
-	verbose         bool
+	config  *config.Config
+	verbose bool
+
+	networkMu       sync.Mutex // serializes EnsureNetwork
 	networkVerified bool
-	overrideFile    string // temp compose override for implicit networking
+
+	mu           sync.Mutex          // guards the fields below
+	overrideFile string              // temp compose override for implicit networking
+	services     map[string][]string // services per project, cached for this run
+	commandLog   io.Writer           // where commands are logged in verbose mode
 }
 
 // NewManager creates a new Docker manager.
 func NewManager(cfg *config.Config, verbose bool) *Manager {
 	return &Manager{
-		config:  cfg,
-		verbose: verbose,
+		config:     cfg,
+		verbose:    verbose,
+		services:   make(map[string][]string),
+		commandLog: os.Stderr,
 	}
 }
 
@@ -48,7 +69,21 @@ func (m *Manager) logCommand(cmd *exec.Cmd) {
 	if !m.verbose {
 		return
 	}
-	fmt.Fprintf(os.Stderr, "\033[90m$ %s\033[0m\n", strings.Join(cmd.Args, " "))
+	m.mu.Lock()
+	w := m.commandLog
+	m.mu.Unlock()
+	fmt.Fprintf(w, "\033[90m$ %s\033[0m\n", strings.Join(cmd.Args, " "))
+}
+
+// SetCommandLog sets where commands are logged in verbose mode (stderr by
+// default), e.g. io.Discard while a TUI owns the terminal. It returns the
+// previous writer.
+func (m *Manager) SetCommandLog(w io.Writer) io.Writer {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	prev := m.commandLog
+	m.commandLog = w
+	return prev
 }
 
 // composeEnv returns the current process environment with IFRIT_SHARED_NETWORK injected.
@@ -66,6 +101,9 @@ func (m *Manager) composeEnv() []string {
 // default network to the shared external network. The file lives in the OS temp
 // dir and is cleaned up automatically on reboot.
 func (m *Manager) ensureOverrideFile() (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if m.overrideFile != "" {
 		return m.overrideFile, nil
 	}
@@ -139,6 +177,9 @@ func (m *Manager) networkExists() (bool, error) {
 }
 
 func (m *Manager) EnsureNetwork() error {
+	m.networkMu.Lock()
+	defer m.networkMu.Unlock()
+
 	if m.networkVerified {
 		return nil
 	}
@@ -224,6 +265,10 @@ func (m *Manager) ComposeUp(projectName string, forceRecreate bool) error {
 		return err
 	}
 
+	if err := m.runHooks(project, projectName, stagePreUp); err != nil {
+		return err
+	}
+
 	ui.Printf("Starting project: %s\n", projectName)
 
 	args := append(baseArgs,
@@ -237,6 +282,12 @@ func (m *Manager) ComposeUp(projectName string, forceRecreate bool) error {
 
 	args = append(args, "--detach")
 
+	// Post-up hooks typically talk to the services (e.g. migrations), so
+	// wait for them to be running/healthy first.
+	if len(m.hooksFor(project, stagePostUp)) > 0 {
+		args = append(args, "--wait")
+	}
+
 	cmd := composeCommand(args...)
 	cmd.Dir = project.Path
 	cmd.Stdout = os.Stdout
@@ -249,7 +300,7 @@ func (m *Manager) ComposeUp(projectName string, forceRecreate bool) error {
 		return fmt.Errorf("failed to start project %s: %w", projectName, err)
 	}
 
-	return nil
+	return m.runHooks(project, projectName, stagePostUp)
 }
 
 // ComposeDown runs docker compose down for a project.
@@ -264,6 +315,10 @@ func (m *Manager) ComposeDown(projectName string, removeVolumes bool) error {
 		return err
 	}
 
+	if err := m.runHooks(project, projectName, stagePreDown); err != nil {
+		return err
+	}
+
 	ui.Printf("Stopping project: %s\n", projectName)
 
 	args := append(baseArgs, "down")
@@ -283,7 +338,7 @@ func (m *Manager) ComposeDown(projectName string, removeVolumes bool) error {
 		return fmt.Errorf("failed to stop project %s: %w", projectName, err)
 	}
 
-	return nil
+	return m.runHooks(project, projectName, stagePostDown)
 }
 
 // ComposeStatus shows the status of a project.
@@ -314,8 +369,39 @@ func (m *Manager) ComposeStatus(projectName string) error {
 	return nil
 }
 
-// ComposeLogs shows logs for a project.
-func (m *Manager) ComposeLogs(projectName string, follow bool, tail string) error {
+// LogsOptions selects the logs shown by ComposeLogs and the logs commands.
+type LogsOptions struct {
+	Follow     bool   // keep streaming new logs (the commands always follow)
+	Tail       string // number of lines from the end, or "all"
+	Since      string // only logs since a timestamp or duration, e.g. "10m"
+	Until      string // only logs before a timestamp or duration
+	Timestamps bool   // prefix lines with their timestamp
+}
+
+// args returns the "docker compose logs" flags for the options.
+func (o LogsOptions) args() []string {
+	var args []string
+	if o.Follow {
+		args = append(args, "--follow")
+	}
+	if o.Tail != "" {
+		args = append(args, "--tail", o.Tail)
+	}
+	if o.Since != "" {
+		args = append(args, "--since", o.Since)
+	}
+	if o.Until != "" {
+		args = append(args, "--until", o.Until)
+	}
+	if o.Timestamps {
+		args = append(args, "--timestamps")
+	}
+	return args
+}
+
+// ComposeLogs writes logs for a project to out, optionally limited to some
+// services.
+func (m *Manager) ComposeLogs(projectName string, opts LogsOptions, out io.Writer, services ...string) error {
 	project, err := m.getProject(projectName)
 	if err != nil {
 		return err
@@ -327,19 +413,13 @@ func (m *Manager) ComposeLogs(projectName string, follow bool, tail string) erro
 	}
 
 	args := append(baseArgs, "logs")
-
-	if follow {
-		args = append(args, "--follow")
-	}
-
-	if tail != "" {
-		args = append(args, "--tail", tail)
-	}
+	args = append(args, opts.args()...)
+	args = append(args, services...)
 
 	cmd := composeCommand(args...)
 	cmd.Dir = project.Path
 	cmd.Env = m.composeEnv()
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = out
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
 
@@ -354,7 +434,7 @@ func (m *Manager) ComposeLogs(projectName string, follow bool, tail string) erro
 // ComposeLogsCmd builds and returns an *exec.Cmd for tailing logs of a project
 // without executing it. The caller is responsible for managing the process
 // lifecycle. This is used by the interactive TUI logs viewer.
-func (m *Manager) ComposeLogsCmd(projectName string, tail string) (*exec.Cmd, error) {
+func (m *Manager) ComposeLogsCmd(projectName string, opts LogsOptions) (*exec.Cmd, error) {
 	project, err := m.getProject(projectName)
 	if err != nil {
 		return nil, err
@@ -365,11 +445,9 @@ func (m *Manager) ComposeLogsCmd(projectName string, tail string) (*exec.Cmd, er
 		return nil, err
 	}
 
-	args := append(baseArgs, "logs", "--follow")
-
-	if tail != "" {
-		args = append(args, "--tail", tail)
-	}
+	opts.Follow = true
+	args := append(baseArgs, "logs")
+	args = append(args, opts.args()...)
 
 	cmd := composeCommand(args...)
 	cmd.Dir = project.Path
@@ -381,7 +459,7 @@ func (m *Manager) ComposeLogsCmd(projectName string, tail string) (*exec.Cmd, er
 // ComposeServiceLogsCmd builds and returns an *exec.Cmd for tailing logs of a
 // single service within a project, without executing it. The caller is
 // responsible for managing the process lifecycle.
-func (m *Manager) ComposeServiceLogsCmd(projectName, serviceName string, tail string) (*exec.Cmd, error) {
+func (m *Manager) ComposeServiceLogsCmd(projectName, serviceName string, opts LogsOptions) (*exec.Cmd, error) {
 	project, err := m.getProject(projectName)
 	if err != nil {
 		return nil, err
@@ -392,12 +470,9 @@ func (m *Manager) ComposeServiceLogsCmd(projectName, serviceName string, tail st
 		return nil, err
 	}
 
-	args := append(baseArgs, "logs", "--follow")
-
-	if tail != "" {
-		args = append(args, "--tail", tail)
-	}
-
+	opts.Follow = true
+	args := append(baseArgs, "logs")
+	args = append(args, opts.args()...)
 	args = append(args, serviceName)
 
 	cmd := composeCommand(args...)
@@ -457,19 +532,14 @@ func (m *Manager) ensureServiceRunning(projectName, serviceName string) error {
 	cmd.Dir = project.Path
 	cmd.Env = m.composeEnv()
 
-	// Capture output instead of printing directly — only show on error.
-	var outBuf, errBuf bytes.Buffer
-	cmd.Stdout = &outBuf
-	cmd.Stderr = &errBuf
-
+	// Capture output instead of printing it, and include it in the error so
+	// the user can diagnose a failure. Printing would also end up on top of
+	// the logs viewer, which opens shells through here.
 	m.logCommand(cmd)
-	if err := cmd.Run(); err != nil {
-		// Show the captured output so the user can diagnose the failure.
-		if outBuf.Len() > 0 {
-			os.Stdout.Write(outBuf.Bytes())
-		}
-		if errBuf.Len() > 0 {
-			os.Stderr.Write(errBuf.Bytes())
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
 		}
 		return fmt.Errorf("failed to start service %s in project %s: %w", serviceName, projectName, err)
 	}
@@ -477,8 +547,20 @@ func (m *Manager) ensureServiceRunning(projectName, serviceName string) error {
 	return nil
 }
 
-// ComposeExec executes a command in a running container.
-func (m *Manager) ComposeExec(projectName, serviceName string, command []string, interactive bool) error {
+// ServiceAction is a compose command acting on a single service.
+type ServiceAction string
+
+const (
+	ServiceRestart ServiceAction = "restart"
+	ServiceStop    ServiceAction = "stop"
+	// ServiceStart creates the service's container if needed.
+	ServiceStart ServiceAction = "start"
+)
+
+// ComposeServiceAction restarts, stops or starts a single service. Output is
+// captured rather than printed, and included in the error on failure, so
+// that it can be used under a TUI.
+func (m *Manager) ComposeServiceAction(projectName, serviceName string, action ServiceAction) error {
 	project, err := m.getProject(projectName)
 	if err != nil {
 		return err
@@ -489,27 +571,148 @@ func (m *Manager) ComposeExec(projectName, serviceName string, command []string,
 		return err
 	}
 
-	// Ensure the service is up and running before exec.
-	if err := m.ensureServiceRunning(projectName, serviceName); err != nil {
-		return err
+	args := append(baseArgs, string(action), serviceName)
+	if action == ServiceStart {
+		args = append(baseArgs, "up", "--detach", serviceName)
 	}
 
-	args := append(baseArgs, "exec")
+	cmd := composeCommand(args...)
+	cmd.Dir = project.Path
+	cmd.Env = m.composeEnv()
 
-	if !interactive {
+	m.logCommand(cmd)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		return fmt.Errorf("failed to %s service %s in project %s: %w", action, serviceName, projectName, err)
+	}
+	return nil
+}
+
+// ExecOptions controls how ComposeExec runs a command in a container.
+type ExecOptions struct {
+	Interactive bool
+	Env         []string // extra KEY=VALUE pairs set in the container
+	User        string   // user to run as, e.g. "root" or "1000:1000"
+	Workdir     string   // working directory inside the container
+	Index       int      // container index for scaled services, 0 for default
+	Privileged  bool     // give extended privileges to the process
+	Mode        ExecMode // how to reach the container, ExecStart if empty
+}
+
+// args returns the "docker compose exec" flags for the options, excluding
+// the TTY setting.
+func (o ExecOptions) args() []string {
+	var args []string
+	if o.User != "" {
+		args = append(args, "--user", o.User)
+	}
+	if o.Workdir != "" {
+		args = append(args, "--workdir", o.Workdir)
+	}
+	if o.Index > 0 {
+		args = append(args, "--index", strconv.Itoa(o.Index))
+	}
+	if o.Privileged {
+		args = append(args, "--privileged")
+	}
+	for _, e := range o.Env {
+		args = append(args, "--env", e)
+	}
+	return args
+}
+
+// ExecMode selects how ComposeExec reaches a service's container.
+type ExecMode string
+
+const (
+	// ExecStart starts the service if needed, then execs into it (default).
+	ExecStart ExecMode = "start"
+	// ExecNoStart execs into the service only if it's already running.
+	ExecNoStart ExecMode = "no-start"
+	// ExecRun uses a throwaway container via "docker compose run --rm".
+	ExecRun ExecMode = "run"
+)
+
+// prepareExec makes sure the service is in the state required by the mode:
+// started for ExecStart, already running for ExecNoStart. ExecRun needs
+// nothing, but doesn't support every exec option.
+func (m *Manager) prepareExec(projectName, serviceName string, opts ExecOptions) error {
+	switch opts.Mode {
+	case ExecNoStart:
+		states, err := m.ServiceStates(projectName)
+		if err != nil {
+			return err
+		}
+		state, ok := states[serviceName]
+		if !ok {
+			state = "not created"
+		}
+		if state != "running" {
+			return fmt.Errorf("service %s in project %s is not running (state: %s)", serviceName, projectName, state)
+		}
+	case ExecRun:
+		if opts.Index > 0 || opts.Privileged {
+			return fmt.Errorf("index and privileged options are not supported in %s mode", ExecRun)
+		}
+	default:
+		return m.ensureServiceRunning(projectName, serviceName)
+	}
+	return nil
+}
+
+// execCmd builds the compose command that runs command in a service, using
+// "exec" or, in ExecRun mode, a one-off "run --rm" container without
+// dependencies. Call prepareExec first.
+func (m *Manager) execCmd(project config.Project, projectName, serviceName string, command []string, opts ExecOptions) (*exec.Cmd, error) {
+	baseArgs, err := m.composeArgs(project, projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	var args []string
+	if opts.Mode == ExecRun {
+		args = append(baseArgs, "run", "--rm", "--no-deps")
+	} else {
+		args = append(baseArgs, "exec")
+	}
+
+	if !opts.Interactive {
 		args = append(args, "--no-TTY")
 	}
 
+	args = append(args, opts.args()...)
 	args = append(args, serviceName)
 	args = append(args, command...)
 
 	cmd := composeCommand(args...)
 	cmd.Dir = project.Path
 	cmd.Env = m.composeEnv()
+	return cmd, nil
+}
+
+// ComposeExec executes a command in a service's container, as selected by
+// opts.Mode.
+func (m *Manager) ComposeExec(projectName, serviceName string, command []string, opts ExecOptions) error {
+	project, err := m.getProject(projectName)
+	if err != nil {
+		return err
+	}
+
+	if err := m.prepareExec(projectName, serviceName, opts); err != nil {
+		return err
+	}
+
+	cmd, err := m.execCmd(project, projectName, serviceName, command, opts)
+	if err != nil {
+		return err
+	}
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
-	if interactive {
+	if opts.Interactive {
 		cmd.Stdin = os.Stdin
 	}
 
@@ -521,8 +724,141 @@ func (m *Manager) ComposeExec(projectName, serviceName string, command []string,
 	return nil
 }
 
-// ComposeServices lists all services in a project.
+// ComposeExecCmd builds and returns an *exec.Cmd that runs a command in a
+// service's container, without executing it or preparing the service (it
+// must already be running unless opts.Mode is ExecRun). The caller is
+// responsible for the process's I/O and lifecycle.
+func (m *Manager) ComposeExecCmd(projectName, serviceName string, command []string, opts ExecOptions) (*exec.Cmd, error) {
+	project, err := m.getProject(projectName)
+	if err != nil {
+		return nil, err
+	}
+	return m.execCmd(project, projectName, serviceName, command, opts)
+}
+
+// ComposeShellCmd prepares the service according to opts.Mode and returns an
+// *exec.Cmd running shell in it, or the shell found by DetectShell if shell
+// is empty. The caller is responsible for the process's I/O and lifecycle.
+func (m *Manager) ComposeShellCmd(projectName, serviceName, shell string, opts ExecOptions) (*exec.Cmd, error) {
+	project, err := m.getProject(projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	if shell == "" {
+		// DetectShell prepares the service too.
+		shell, err = m.DetectShell(projectName, serviceName, opts)
+		if err != nil {
+			return nil, err
+		}
+	} else if err := m.prepareExec(projectName, serviceName, opts); err != nil {
+		return nil, err
+	}
+
+	return m.execCmd(project, projectName, serviceName, []string{shell}, opts)
+}
+
+// ComposeShell runs shell in a service's container, or the shell found by
+// DetectShell if shell is empty, preparing the service only once.
+func (m *Manager) ComposeShell(projectName, serviceName, shell string, opts ExecOptions) error {
+	cmd, err := m.ComposeShellCmd(projectName, serviceName, shell, opts)
+	if err != nil {
+		return err
+	}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if opts.Interactive {
+		cmd.Stdin = os.Stdin
+	}
+
+	m.logCommand(cmd)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to exec into service %s in project %s: %w", serviceName, projectName, err)
+	}
+
+	return nil
+}
+
+// ComposeCopy copies files between a service container and the host using
+// "docker compose cp". Exactly one of src and dst must be of the form
+// "service:path"; the other is a host path, which should be absolute since
+// compose runs in the project directory, or "-" for a tar stream on stdin or
+// stdout. Index selects the container of a
+// scaled service (0 for the default).
+func (m *Manager) ComposeCopy(projectName, src, dst string, index int) error {
+	project, err := m.getProject(projectName)
+	if err != nil {
+		return err
+	}
+
+	baseArgs, err := m.composeArgs(project, projectName)
+	if err != nil {
+		return err
+	}
+
+	args := append(baseArgs, "cp")
+
+	if index > 0 {
+		args = append(args, "--index", strconv.Itoa(index))
+	}
+
+	args = append(args, src, dst)
+
+	cmd := composeCommand(args...)
+	cmd.Dir = project.Path
+	cmd.Env = m.composeEnv()
+	cmd.Stdin = os.Stdin // for a source of "-"
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	m.logCommand(cmd)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to copy %s to %s in project %s: %w", src, dst, projectName, err)
+	}
+
+	return nil
+}
+
+// HostExec runs a command on the host with the same environment as compose
+// commands plus the given KEY=VALUE pairs. It runs in the project directory
+// when projectName is set, otherwise in the current directory.
+func (m *Manager) HostExec(projectName string, command []string, env []string) error {
+	cmd := exec.Command(command[0], command[1:]...)
+
+	if projectName != "" {
+		project, err := m.getProject(projectName)
+		if err != nil {
+			return err
+		}
+		cmd.Dir = project.Path
+	}
+
+	cmd.Env = append(m.composeEnv(), env...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	m.logCommand(cmd)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run %s: %w", command[0], err)
+	}
+
+	return nil
+}
+
+// ComposeServices lists all services in a project. Results are cached in
+// memory for the lifetime of the manager, and on disk in the state directory
+// keyed by a hash of the project's compose inputs, so the (slow) "docker
+// compose config" only runs when something relevant has changed.
 func (m *Manager) ComposeServices(projectName string) ([]string, error) {
+	m.mu.Lock()
+	services, ok := m.services[projectName]
+	m.mu.Unlock()
+	if ok {
+		return services, nil
+	}
+
 	project, err := m.getProject(projectName)
 	if err != nil {
 		return nil, err
@@ -533,6 +869,16 @@ func (m *Manager) ComposeServices(projectName string) ([]string, error) {
 		return nil, err
 	}
 
+	key, keyErr := m.servicesCacheKey(project, baseArgs)
+	cachePath, pathErr := m.servicesCachePath(project, projectName)
+	cacheable := keyErr == nil && pathErr == nil
+	if cacheable {
+		if services, ok := readServicesCache(cachePath, key); ok {
+			m.setServices(projectName, services)
+			return services, nil
+		}
+	}
+
 	args := append(baseArgs, "config", "--services")
 
 	cmd := composeCommand(args...)
@@ -544,7 +890,7 @@ func (m *Manager) ComposeServices(projectName string) ([]string, error) {
 		return nil, fmt.Errorf("failed to list services for project %s: %w", projectName, err)
 	}
 
-	services := []string{}
+	services = []string{}
 	lines := strings.SplitSeq(strings.TrimSpace(string(output)), "\n")
 	for line := range lines {
 		if line != "" {
@@ -552,5 +898,248 @@ func (m *Manager) ComposeServices(projectName string) ([]string, error) {
 		}
 	}
 
+	if cacheable {
+		writeServicesCache(cachePath, key, services)
+	}
+	m.setServices(projectName, services)
+
 	return services, nil
 }
+
+// setServices memoizes the services of a project for this run.
+func (m *Manager) setServices(projectName string, services []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.services[projectName] = services
+}
+
+// Container describes a container of a compose project, as reported by
+// "docker compose ps".
+type Container struct {
+	Name       string      `json:"Name"`
+	Service    string      `json:"Service"`
+	State      string      `json:"State"`  // e.g. "running", "exited", "restarting"
+	Health     string      `json:"Health"` // e.g. "healthy", empty without a healthcheck
+	Labels     string      `json:"Labels"` // comma-separated key=value pairs
+	Publishers []Publisher `json:"Publishers"`
+}
+
+// Publisher is a container port and, if published, its host binding.
+type Publisher struct {
+	URL           string `json:"URL"` // host address, e.g. "0.0.0.0"
+	TargetPort    int    `json:"TargetPort"`
+	PublishedPort int    `json:"PublishedPort"` // 0 if not published
+	Protocol      string `json:"Protocol"`
+}
+
+// Label returns the value of a container label, or "" if it isn't set.
+func (c Container) Label(key string) string {
+	for pair := range strings.SplitSeq(c.Labels, ",") {
+		if k, v, ok := strings.Cut(pair, "="); ok && k == key {
+			return v
+		}
+	}
+	return ""
+}
+
+// ProjectContainers lists the containers of every project, keyed by project
+// name. Projects whose containers can't be listed are left out, with a
+// warning on stderr, so that one broken project doesn't hide the others.
+func (m *Manager) ProjectContainers() map[string][]Container {
+	all := make(map[string][]Container)
+	for _, projectName := range m.config.GetProjects() {
+		containers, err := m.ComposeContainers(projectName)
+		if err != nil {
+			ui.Fprintf(os.Stderr, "Warning: %v\n", err)
+			continue
+		}
+		all[projectName] = containers
+	}
+	return all
+}
+
+// ComposeContainers lists all containers of a project, including stopped ones.
+func (m *Manager) ComposeContainers(projectName string) ([]Container, error) {
+	project, err := m.getProject(projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	baseArgs, err := m.composeArgs(project, projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	args := append(baseArgs, "ps", "--all", "--format", "json")
+
+	cmd := composeCommand(args...)
+	cmd.Dir = project.Path
+	cmd.Env = m.composeEnv()
+	m.logCommand(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers for project %s: %w", projectName, err)
+	}
+
+	containers, err := parseContainers(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse containers for project %s: %w", projectName, err)
+	}
+	return containers, nil
+}
+
+// parseContainers parses "docker compose ps --format json" output, which is a
+// JSON array in older Compose versions and one object per line in newer ones.
+func parseContainers(output []byte) ([]Container, error) {
+	output = bytes.TrimSpace(output)
+	containers := []Container{}
+	if len(output) == 0 {
+		return containers, nil
+	}
+
+	if output[0] == '[' {
+		if err := json.Unmarshal(output, &containers); err != nil {
+			return nil, err
+		}
+		return containers, nil
+	}
+
+	for line := range bytes.SplitSeq(output, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var c Container
+		if err := json.Unmarshal(line, &c); err != nil {
+			return nil, err
+		}
+		containers = append(containers, c)
+	}
+	return containers, nil
+}
+
+// ErrNoShell is returned by DetectShell when a container has none of the
+// candidate shells, e.g. because it's built from a distroless image.
+var ErrNoShell = errors.New("no shell found in container")
+
+// shellCandidates are the shells probed by DetectShell, in order of preference.
+var shellCandidates = []string{"bash", "zsh", "ash", "sh"}
+
+// DetectShell prepares the service according to opts.Mode and returns the
+// first available shell from shellCandidates, probing each one by running it
+// with the given options (so that mode, user and index match the real exec).
+// In ExecRun mode, where each probe creates a container, a single probe asks
+// sh for the best shell instead.
+func (m *Manager) DetectShell(projectName, serviceName string, opts ExecOptions) (string, error) {
+	project, err := m.getProject(projectName)
+	if err != nil {
+		return "", err
+	}
+
+	if err := m.prepareExec(projectName, serviceName, opts); err != nil {
+		return "", err
+	}
+
+	opts.Interactive = false
+	if opts.Mode == ExecRun {
+		return m.probeShell(project, projectName, serviceName, opts)
+	}
+	for _, shell := range shellCandidates {
+		cmd, err := m.execCmd(project, projectName, serviceName, []string{shell, "-c", "exit 0"}, opts)
+		if err != nil {
+			return "", err
+		}
+
+		m.logCommand(cmd)
+		if err := cmd.Run(); err == nil {
+			return shell, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s/%s (tried %s)", ErrNoShell, projectName, serviceName, strings.Join(shellCandidates, ", "))
+}
+
+// probeShell finds the best shell from shellCandidates with a single run of
+// sh, which is the last candidate.
+func (m *Manager) probeShell(project config.Project, projectName, serviceName string, opts ExecOptions) (string, error) {
+	var probes []string
+	for _, shell := range shellCandidates[:len(shellCandidates)-1] {
+		probes = append(probes, "command -v "+shell)
+	}
+	script := strings.Join(append(probes, "echo sh"), " || ")
+
+	cmd, err := m.execCmd(project, projectName, serviceName, []string{"sh", "-c", script}, opts)
+	if err != nil {
+		return "", err
+	}
+
+	m.logCommand(cmd)
+	output, err := cmd.Output()
+	lines := strings.Fields(string(output))
+	if err != nil || len(lines) == 0 {
+		return "", fmt.Errorf("%w: %s/%s (tried sh)", ErrNoShell, projectName, serviceName)
+	}
+	// Compose may print to stdout while creating the container.
+	return lines[len(lines)-1], nil
+}
+
+// ServiceStates returns the state of each service in a project that has a
+// container. Services without a container are absent from the map.
+func (m *Manager) ServiceStates(projectName string) (map[string]string, error) {
+	containers, err := m.ComposeContainers(projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make(map[string]string, len(containers))
+	for _, c := range containers {
+		// With scaled services, a single running replica is enough.
+		if states[c.Service] != "running" {
+			states[c.Service] = c.State
+		}
+	}
+	return states, nil
+}
+
+// NetworkEndpoint is a container's attachment to the shared network.
+type NetworkEndpoint struct {
+	IPAddress string   `json:"IPAddress"`
+	Aliases   []string `json:"Aliases"`
+	DNSNames  []string `json:"DNSNames"`
+}
+
+// SharedNetworkEndpoints returns the shared network endpoint of each of the
+// given containers, keyed by container name. Containers that aren't attached
+// to the shared network are absent from the map.
+func (m *Manager) SharedNetworkEndpoints(containers ...string) (map[string]NetworkEndpoint, error) {
+	endpoints := map[string]NetworkEndpoint{}
+	if len(containers) == 0 {
+		return endpoints, nil
+	}
+
+	args := append([]string{"inspect", "--format", "{{json .Name}} {{json .NetworkSettings.Networks}}"}, containers...)
+	cmd := exec.Command("docker", args...)
+	m.logCommand(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect containers: %w", err)
+	}
+
+	for line := range strings.SplitSeq(strings.TrimSpace(string(output)), "\n") {
+		nameJSON, networksJSON, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		var name string
+		var networks map[string]NetworkEndpoint
+		if err := json.Unmarshal([]byte(nameJSON), &name); err != nil {
+			return nil, fmt.Errorf("failed to parse container inspect output: %w", err)
+		}
+		if err := json.Unmarshal([]byte(networksJSON), &networks); err != nil {
+			return nil, fmt.Errorf("failed to parse container inspect output: %w", err)
+		}
+		if ep, ok := networks[m.config.SharedNetwork]; ok {
+			endpoints[strings.TrimPrefix(name, "/")] = ep
+		}
+	}
+	return endpoints, nil
+}
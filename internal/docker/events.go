@@ -0,0 +1,75 @@
+package docker
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// containerActions are the container events that change a service's state.
+var containerActions = []string{"create", "start", "restart", "die", "stop", "pause", "unpause", "destroy"}
+
+// ContainerEvent is a change to a container of a configured project.
+type ContainerEvent struct {
+	Project string // project name, without the name prefix
+	Service string
+	Action  string // e.g. "start", "die", "destroy"
+}
+
+// WatchContainers calls onEvent whenever a container of any project managed
+// by ifrit is created, started, stopped or removed, until ctx is done.
+func (m *Manager) WatchContainers(ctx context.Context, onEvent func(ContainerEvent)) error {
+	args := []string{"events",
+		"--filter", "type=container",
+		"--filter", "label=com.docker.compose.project",
+		"--format", "{{json .}}",
+	}
+	for _, action := range containerActions {
+		args = append(args, "--filter", "event="+action)
+	}
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	m.logCommand(cmd)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to watch docker events: %w", err)
+	}
+
+	prefix := m.config.NamePrefix + "_"
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		var event struct {
+			Action string `json:"Action"`
+			Actor  struct {
+				Attributes map[string]string `json:"Attributes"`
+			} `json:"Actor"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		// Label filters can't be OR'ed, so match the projects here.
+		attrs := event.Actor.Attributes
+		projectName, ok := strings.CutPrefix(attrs["com.docker.compose.project"], prefix)
+		if !ok {
+			continue
+		}
+		if _, ok := m.config.Projects[projectName]; !ok {
+			continue
+		}
+		onEvent(ContainerEvent{
+			Project: projectName,
+			Service: attrs["com.docker.compose.service"],
+			Action:  event.Action,
+		})
+	}
+
+	if err := cmd.Wait(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("docker events failed: %w", err)
+	}
+	return nil
+}
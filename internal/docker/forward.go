@@ -0,0 +1,122 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+
+	"github.com/khueue/ifrit/internal/ui"
+)
+
+// DefaultForwardImage is the relay image used by Forward unless configured.
+// Its entrypoint must be socat.
+const DefaultForwardImage = "alpine/socat:latest"
+
+// forwardLabel marks forwarding relays. Its value is the config's name prefix.
+const forwardLabel = "ifrit.forward"
+
+// Forward relays a host port to a port on a container reachable on the
+// shared network (by container name or alias), using a small socat container
+// attached to that network. It blocks until interrupted (Ctrl-C) or until the
+// relay exits, and removes the relay before returning.
+func (m *Manager) Forward(bindAddr string, localPort int, host string, port int, image string) error {
+	exists, err := m.networkExists()
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("shared network %s does not exist; start a project first", m.config.SharedNetwork)
+	}
+
+	name := fmt.Sprintf("%s_forward_%d", m.config.NamePrefix, localPort)
+	remove := func(flags ...string) {
+		rm := exec.Command("docker", slices.Concat([]string{"rm"}, flags, []string{name})...)
+		m.logCommand(rm)
+		_ = rm.Run()
+	}
+
+	// A relay left behind by a crashed session would hold the name, but one
+	// that is running belongs to another session.
+	state, err := m.relayState(name)
+	if err != nil {
+		return err
+	}
+	if state == "running" {
+		return fmt.Errorf("port %d already forwarded (relay %s is running)", localPort, name)
+	}
+	if state != "" {
+		remove()
+	}
+
+	args := []string{
+		"run", "--detach",
+		"--name", name,
+		"--network", m.config.SharedNetwork,
+		"--publish", fmt.Sprintf("%s:%d:%d", bindAddr, localPort, port),
+		"--label", fmt.Sprintf("%s=%s", forwardLabel, m.config.NamePrefix),
+		image,
+		fmt.Sprintf("TCP-LISTEN:%d,fork,reuseaddr", port),
+		fmt.Sprintf("TCP:%s:%d", host, port),
+	}
+
+	cmd := exec.Command("docker", args...)
+	cmd.Stderr = os.Stderr
+	m.logCommand(cmd)
+	if err := cmd.Run(); err != nil {
+		// Clean up a relay created without starting, e.g. if the port is
+		// taken, but not one that another session started meanwhile.
+		remove()
+		return fmt.Errorf("failed to start relay: %w", err)
+	}
+	defer remove("--force")
+
+	ui.Printf("Forwarding %s:%d -> %s:%d (Ctrl-C to stop)\n", bindAddr, localPort, host, port)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// "docker wait" returns when the relay exits on its own.
+	exited := make(chan string, 1)
+	go func() {
+		wait := exec.Command("docker", "wait", name)
+		m.logCommand(wait)
+		out, _ := wait.Output()
+		exited <- strings.TrimSpace(string(out))
+	}()
+
+	select {
+	case <-ctx.Done():
+		ui.Println()
+		ui.Println("Stopping forward.")
+		return nil
+	case code := <-exited:
+		logs := exec.Command("docker", "logs", "--tail", "20", name)
+		logs.Stdout = os.Stderr
+		logs.Stderr = os.Stderr
+		m.logCommand(logs)
+		_ = logs.Run()
+		return fmt.Errorf("relay exited unexpectedly (exit status %s)", code)
+	}
+}
+
+// relayState returns the state of the forwarding relay with the given name,
+// e.g. "running" or "exited", or "" if there is none. Only containers with
+// this config's forward label are considered.
+func (m *Manager) relayState(name string) (string, error) {
+	cmd := exec.Command("docker", "ps", "--all",
+		"--filter", "name=^/"+name+"$",
+		"--filter", fmt.Sprintf("label=%s=%s", forwardLabel, m.config.NamePrefix),
+		"--format", "{{.State}}",
+	)
+	m.logCommand(cmd)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to look up relay %s: %w", name, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
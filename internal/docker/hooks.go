@@ -0,0 +1,97 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+
+	"github.com/khueue/ifrit/internal/config"
+	"github.com/khueue/ifrit/internal/ui"
+)
+
+// Lifecycle stages at which hooks run.
+const (
+	stagePreUp    = "pre_up"
+	stagePostUp   = "post_up"
+	stagePreDown  = "pre_down"
+	stagePostDown = "post_down"
+)
+
+// hooksFor returns the hooks to run for a project at the given stage. Global
+// hooks come first, followed by the project's own hooks.
+func (m *Manager) hooksFor(project config.Project, stage string) []config.Hook {
+	pick := func(h config.Hooks) []config.Hook {
+		switch stage {
+		case stagePreUp:
+			return h.PreUp
+		case stagePostUp:
+			return h.PostUp
+		case stagePreDown:
+			return h.PreDown
+		case stagePostDown:
+			return h.PostDown
+		}
+		return nil
+	}
+	return slices.Concat(pick(m.config.Hooks), pick(project.Hooks))
+}
+
+// runHooks runs all hooks for a project at the given stage, stopping at the
+// first failure.
+func (m *Manager) runHooks(project config.Project, projectName, stage string) error {
+	for _, hook := range m.hooksFor(project, stage) {
+		if err := m.runHook(project, projectName, stage, hook); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runHook runs a single hook, either on the host in the project directory or
+// inside the hook's service. Pre-up and post-down service hooks use a one-off
+// container, since the service isn't running yet, or anymore.
+func (m *Manager) runHook(project config.Project, projectName, stage string, hook config.Hook) error {
+	timeout := hook.RunTimeout()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if hook.Service == "" {
+		ui.Printf("Running %s hook for %s: %s\n", stage, projectName, hook.Command)
+		cmd = exec.CommandContext(ctx, "sh", "-c", hook.Command)
+	} else {
+		ui.Printf("Running %s hook for %s/%s: %s\n", stage, projectName, hook.Service, hook.Command)
+		baseArgs, err := m.composeArgs(project, projectName)
+		if err != nil {
+			return err
+		}
+
+		var args []string
+		if stage == stagePreUp || stage == stagePostDown {
+			args = append(baseArgs, "run", "--rm", "--no-deps", "--no-TTY")
+		} else {
+			args = append(baseArgs, "exec", "--no-TTY")
+		}
+		args = append(args, hook.Service, "sh", "-c", hook.Command)
+
+		cmd = composeCommandContext(ctx, args...)
+	}
+
+	cmd.Dir = project.Path
+	cmd.Env = append(m.composeEnv(), fmt.Sprintf("IFRIT_PROJECT=%s", projectName))
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	m.logCommand(cmd)
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%s hook for project %s timed out after %s", stage, projectName, timeout)
+		}
+		return fmt.Errorf("%s hook for project %s failed: %w", stage, projectName, err)
+	}
+
+	return nil
+}
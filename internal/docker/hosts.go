@@ -0,0 +1,110 @@
+package docker
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// shortIDPattern matches the short container IDs Docker adds as DNS names.
+var shortIDPattern = regexp.MustCompile(`^[0-9a-f]{12}$`)
+
+// HostEntry maps a name resolvable on the shared network to an address.
+type HostEntry struct {
+	Address string
+	Name    string
+}
+
+// HostEntries returns a hosts-file entry for every container name and alias
+// on the shared network of the running project containers. Names are mapped
+// to the container's IP, or to 127.0.0.1 if loopbackPublished is set and the
+// container publishes ports. Names that resolve to more than one address
+// (e.g. a service name used in several projects) and short container IDs
+// are left out. Entries are sorted by name. Projects whose containers can't
+// be listed are skipped with a warning, so that the others stay up to date.
+func (m *Manager) HostEntries(loopbackPublished bool) ([]HostEntry, error) {
+	projectContainers := m.ProjectContainers()
+	var containers []Container
+	for _, projectName := range m.config.GetProjects() {
+		for _, c := range projectContainers[projectName] {
+			if c.State == "running" {
+				containers = append(containers, c)
+			}
+		}
+	}
+
+	names := make([]string, len(containers))
+	for i, c := range containers {
+		names[i] = c.Name
+	}
+	endpoints, err := m.SharedNetworkEndpoints(names...)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := map[string][]string{}
+	for _, c := range containers {
+		ep, ok := endpoints[c.Name]
+		if !ok || ep.IPAddress == "" {
+			continue
+		}
+
+		addr := ep.IPAddress
+		if loopbackPublished && slices.ContainsFunc(c.Publishers, func(p Publisher) bool { return p.PublishedPort != 0 }) {
+			addr = "127.0.0.1"
+		}
+
+		for _, name := range slices.Concat([]string{c.Name}, ep.Aliases, ep.DNSNames) {
+			if name == "" || shortIDPattern.MatchString(name) || slices.Contains(addresses[name], addr) {
+				continue
+			}
+			addresses[name] = append(addresses[name], addr)
+		}
+	}
+
+	var entries []HostEntry
+	for name, addrs := range addresses {
+		if len(addrs) == 1 {
+			entries = append(entries, HostEntry{Address: addrs[0], Name: name})
+		}
+	}
+	slices.SortFunc(entries, func(a, b HostEntry) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return entries, nil
+}
+
+// WatchNetwork calls onChange whenever a container connects to or
+// disconnects from the shared network, until ctx is done.
+func (m *Manager) WatchNetwork(ctx context.Context, onChange func()) error {
+	cmd := exec.CommandContext(ctx, "docker", "events",
+		"--filter", "type=network",
+		"--filter", "network="+m.config.SharedNetwork,
+		"--format", "{{.Action}}",
+	)
+	m.logCommand(cmd)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to watch docker events: %w", err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		switch strings.TrimSpace(scanner.Text()) {
+		case "connect", "disconnect":
+			onChange()
+		}
+	}
+
+	if err := cmd.Wait(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("docker events failed: %w", err)
+	}
+	return nil
+}
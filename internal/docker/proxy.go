@@ -0,0 +1,115 @@
+package docker
+
+import (
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/khueue/ifrit/internal/ui"
+)
+
+// Compose service labels that expose a service through "ifrit proxy".
+const (
+	proxyPortLabel = "ifrit.proxy.port"
+	proxyHostLabel = "ifrit.proxy.host"
+)
+
+// proxyCandidate is a route matched to a running container.
+type proxyCandidate struct {
+	host      string
+	container Container
+	port      int
+}
+
+// ProxyRoutes returns the backend URL for every routable name: the routes in
+// the config plus services labeled with ifrit.proxy.port (and optionally
+// ifrit.proxy.host), for running containers only. A published port is
+// reached through the host; otherwise the container's IP on the shared
+// network is used, which requires the host to reach container IPs (e.g.
+// Linux, but not Docker Desktop). Projects whose containers can't be listed,
+// and invalid labels, are skipped with a warning.
+func (m *Manager) ProxyRoutes() (map[string]*url.URL, error) {
+	return m.ProxyRoutesFor(m.ProjectContainers())
+}
+
+// ProxyRoutesFor is ProxyRoutes for containers already listed by
+// ProjectContainers.
+func (m *Manager) ProxyRoutesFor(projectContainers map[string][]Container) (map[string]*url.URL, error) {
+	var candidates []proxyCandidate
+
+	for _, projectName := range m.config.GetProjects() {
+		for _, c := range projectContainers[projectName] {
+			if c.State != "running" {
+				continue
+			}
+
+			for _, route := range m.config.Proxy.Routes {
+				if route.Project == projectName && route.Service == c.Service {
+					candidates = append(candidates, proxyCandidate{route.Host, c, route.Port})
+				}
+			}
+
+			if v := c.Label(proxyPortLabel); v != "" {
+				port, err := strconv.Atoi(v)
+				if err != nil {
+					ui.Fprintf(os.Stderr, "Warning: invalid %s label on %s: %q\n", proxyPortLabel, c.Name, v)
+					continue
+				}
+				host := c.Label(proxyHostLabel)
+				if host == "" {
+					host = c.Service + "." + projectName
+				}
+				candidates = append(candidates, proxyCandidate{host, c, port})
+			}
+		}
+	}
+
+	// Look up shared network IPs for routes without a published port.
+	var unpublished []string
+	for _, cand := range candidates {
+		if publishedAddr(cand.container, cand.port) == "" {
+			unpublished = append(unpublished, cand.container.Name)
+		}
+	}
+	endpoints, err := m.SharedNetworkEndpoints(unpublished...)
+	if err != nil {
+		return nil, err
+	}
+
+	routes := make(map[string]*url.URL, len(candidates))
+	for _, cand := range candidates {
+		// With scaled services, the first replica wins.
+		if _, ok := routes[cand.host]; ok {
+			continue
+		}
+
+		addr := publishedAddr(cand.container, cand.port)
+		if addr == "" {
+			ep, ok := endpoints[cand.container.Name]
+			if !ok || ep.IPAddress == "" {
+				continue
+			}
+			addr = net.JoinHostPort(ep.IPAddress, strconv.Itoa(cand.port))
+		}
+		routes[cand.host] = &url.URL{Scheme: "http", Host: addr}
+	}
+
+	return routes, nil
+}
+
+// publishedAddr returns the host address a container port is published on,
+// or "" if it isn't published.
+func publishedAddr(c Container, port int) string {
+	for _, p := range c.Publishers {
+		if p.TargetPort != port || p.PublishedPort == 0 || p.Protocol != "tcp" {
+			continue
+		}
+		host := p.URL
+		if ip := net.ParseIP(host); host == "" || ip != nil && ip.IsUnspecified() {
+			host = "127.0.0.1"
+		}
+		return net.JoinHostPort(host, strconv.Itoa(p.PublishedPort))
+	}
+	return ""
+}
@@ -0,0 +1,202 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"maps"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoRoute is returned by a Resolver when no backend serves a name.
+var ErrNoRoute = errors.New("no route")
+
+// Resolver maps a route name, i.e. the request host without the port and
+// domain suffix (e.g. "api.backend"), to the backend URL serving it.
+type Resolver interface {
+	Resolve(ctx context.Context, name string) (*url.URL, error)
+	// Names returns all currently routable names, for the index page.
+	Names(ctx context.Context) ([]string, error)
+}
+
+// targetKey is the request context key holding the resolved backend URL.
+type targetKey struct{}
+
+// Proxy is an http.Handler that routes requests for "<name>.<domain>" to the
+// backend the resolver returns for name. Requests for the bare domain get an
+// index page linking to every route.
+type Proxy struct {
+	domain   string
+	resolver Resolver
+	rp       *httputil.ReverseProxy
+}
+
+// New creates a proxy for hosts under domain (e.g. "localhost").
+func New(domain string, resolver Resolver) *Proxy {
+	p := &Proxy{
+		domain:   strings.ToLower(strings.Trim(domain, ".")),
+		resolver: resolver,
+	}
+	p.rp = &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(pr.In.Context().Value(targetKey{}).(*url.URL))
+			pr.SetXForwarded()
+			// Keep the original host, so backends generate correct links.
+			pr.Out.Host = pr.In.Host
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, fmt.Sprintf("ifrit proxy: backend for %s unavailable: %v", r.Host, err), http.StatusBadGateway)
+		},
+	}
+	return p
+}
+
+// routeName returns the route name for a request host, and whether the host
+// is under the proxy's domain at all.
+func (p *Proxy) routeName(host string) (string, bool) {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	if host == p.domain {
+		return "", true
+	}
+	name, ok := strings.CutSuffix(host, "."+p.domain)
+	return name, ok
+}
+
+// ServeHTTP implements http.Handler.
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name, ok := p.routeName(r.Host)
+	if !ok {
+		http.Error(w, fmt.Sprintf("ifrit proxy: host %s is not under %s", r.Host, p.domain), http.StatusNotFound)
+		return
+	}
+	if name == "" {
+		p.serveIndex(w, r)
+		return
+	}
+
+	target, err := p.resolver.Resolve(r.Context(), name)
+	if errors.Is(err, ErrNoRoute) {
+		http.Error(w, fmt.Sprintf("ifrit proxy: no route for %s", name), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("ifrit proxy: failed to resolve %s: %v", name, err), http.StatusBadGateway)
+		return
+	}
+
+	p.rp.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), targetKey{}, target)))
+}
+
+// serveIndex lists every routable name as a link.
+func (p *Proxy) serveIndex(w http.ResponseWriter, r *http.Request) {
+	names, err := p.resolver.Names(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("ifrit proxy: failed to list routes: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	port := ""
+	if _, hostPort, err := net.SplitHostPort(r.Host); err == nil {
+		port = ":" + hostPort
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, "<!doctype html><title>ifrit proxy</title><h1>ifrit proxy</h1><ul>")
+	for _, name := range names {
+		u := fmt.Sprintf("http://%s.%s%s/", name, p.domain, port)
+		fmt.Fprintf(w, `<li><a href="%s">%s</a></li>`, html.EscapeString(u), html.EscapeString(u))
+	}
+	fmt.Fprint(w, "</ul>")
+}
+
+// Table is a Resolver backed by a function that loads the full route table.
+// The table is reloaded when older than TTL, and on a miss at most once per
+// second, so that newly started services are picked up quickly. Loads run
+// without holding the table, one at a time.
+type Table struct {
+	Load func(ctx context.Context) (map[string]*url.URL, error)
+	TTL  time.Duration
+
+	mu       sync.Mutex
+	routes   map[string]*url.URL
+	loadedAt time.Time
+	loading  chan struct{} // closed when the load in progress finishes
+}
+
+// Resolve implements Resolver.
+func (t *Table) Resolve(ctx context.Context, name string) (*url.URL, error) {
+	routes, err := t.refresh(ctx, t.TTL)
+	if err != nil {
+		return nil, err
+	}
+	if u, ok := routes[name]; ok {
+		return u, nil
+	}
+
+	routes, err = t.refresh(ctx, time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if u, ok := routes[name]; ok {
+		return u, nil
+	}
+	return nil, ErrNoRoute
+}
+
+// Names implements Resolver.
+func (t *Table) Names(ctx context.Context) ([]string, error) {
+	routes, err := t.refresh(ctx, t.TTL)
+	if err != nil {
+		return nil, err
+	}
+	return slices.Sorted(maps.Keys(routes)), nil
+}
+
+// refresh returns the routes, reloading them first if they are older than
+// maxAge (or were never loaded). A caller that finds a load in progress waits
+// for it instead of starting another.
+func (t *Table) refresh(ctx context.Context, maxAge time.Duration) (map[string]*url.URL, error) {
+	t.mu.Lock()
+	for t.loading != nil {
+		loading := t.loading
+		t.mu.Unlock()
+		select {
+		case <-loading:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		t.mu.Lock()
+	}
+	if t.routes != nil && time.Since(t.loadedAt) <= maxAge {
+		defer t.mu.Unlock()
+		return t.routes, nil
+	}
+	loading := make(chan struct{})
+	t.loading = loading
+	t.mu.Unlock()
+
+	routes, err := t.Load(ctx)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.loading = nil
+	close(loading)
+	if err != nil {
+		return nil, err
+	}
+	t.routes = routes
+	t.loadedAt = time.Now()
+	return routes, nil
+}
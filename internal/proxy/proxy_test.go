@@ -0,0 +1,276 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stubResolver resolves names from a fixed map.
+type stubResolver map[string]*url.URL
+
+func (r stubResolver) Resolve(_ context.Context, name string) (*url.URL, error) {
+	if u, ok := r[name]; ok {
+		return u, nil
+	}
+	return nil, ErrNoRoute
+}
+
+func (r stubResolver) Names(context.Context) ([]string, error) {
+	return slices.Sorted(maps.Keys(r)), nil
+}
+
+// echoed is what an echo backend reports about the request it received.
+type echoed struct {
+	Backend        string
+	Host           string
+	Path           string
+	ForwardedFor   string
+	ForwardedHost  string
+	ForwardedProto string
+}
+
+// newEcho starts a backend that answers with an echoed request as JSON.
+func newEcho(t *testing.T, backend string) *url.URL {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(echoed{
+			Backend:        backend,
+			Host:           r.Host,
+			Path:           r.URL.Path,
+			ForwardedFor:   r.Header.Get("X-Forwarded-For"),
+			ForwardedHost:  r.Header.Get("X-Forwarded-Host"),
+			ForwardedProto: r.Header.Get("X-Forwarded-Proto"),
+		})
+	}))
+	t.Cleanup(srv.Close)
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+// newProxy serves a proxy for "localhost" with routes to two echo backends
+// and one that is down.
+func newProxy(t *testing.T) *httptest.Server {
+	t.Helper()
+	down := httptest.NewServer(http.NotFoundHandler())
+	downURL, _ := url.Parse(down.URL)
+	down.Close()
+
+	p := New("localhost", stubResolver{
+		"api.backend": newEcho(t, "api"),
+		"web":         newEcho(t, "web"),
+		"down":        downURL,
+	})
+	srv := httptest.NewServer(p)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// get requests path from the proxy with the given Host header.
+func get(t *testing.T, srv *httptest.Server, host, path string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Host = host
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+func TestRouting(t *testing.T) {
+	srv := newProxy(t)
+
+	tests := []struct {
+		host    string
+		backend string
+	}{
+		{"api.backend.localhost", "api"},
+		{"web.localhost", "web"},
+		{"API.Backend.LocalHost", "api"},
+		{"api.backend.localhost:8080", "api"},
+		{"web.localhost.:8080", "web"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			code, body := get(t, srv, tt.host, "/some/path")
+			if code != http.StatusOK {
+				t.Fatalf("status = %d, want %d (body %q)", code, http.StatusOK, body)
+			}
+			var got echoed
+			if err := json.Unmarshal([]byte(body), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Backend != tt.backend {
+				t.Errorf("backend = %q, want %q", got.Backend, tt.backend)
+			}
+			if got.Path != "/some/path" {
+				t.Errorf("path = %q, want %q", got.Path, "/some/path")
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	srv := newProxy(t)
+
+	tests := []struct {
+		name string
+		host string
+		code int
+	}{
+		{"unknown route", "nope.localhost", http.StatusNotFound},
+		{"unknown nested route", "web.other.localhost", http.StatusNotFound},
+		{"off-domain host", "api.backend.example.com", http.StatusNotFound},
+		{"domain as suffix of a label", "weblocalhost", http.StatusNotFound},
+		{"backend down", "down.localhost", http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, body := get(t, srv, tt.host, "/")
+			if code != tt.code {
+				t.Errorf("status = %d, want %d (body %q)", code, tt.code, body)
+			}
+		})
+	}
+}
+
+func TestIndex(t *testing.T) {
+	srv := newProxy(t)
+
+	code, body := get(t, srv, "localhost:8080", "/")
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", code, http.StatusOK)
+	}
+	for _, link := range []string{
+		"http://api.backend.localhost:8080/",
+		"http://down.localhost:8080/",
+		"http://web.localhost:8080/",
+	} {
+		if !strings.Contains(body, `<a href="`+link+`">`) {
+			t.Errorf("index is missing a link to %s:\n%s", link, body)
+		}
+	}
+}
+
+func TestForwardedHeaders(t *testing.T) {
+	srv := newProxy(t)
+
+	_, body := get(t, srv, "api.backend.localhost:8080", "/")
+	var got echoed
+	if err := json.Unmarshal([]byte(body), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	want := echoed{
+		Backend:        "api",
+		Host:           "api.backend.localhost:8080",
+		Path:           "/",
+		ForwardedFor:   "127.0.0.1",
+		ForwardedHost:  "api.backend.localhost:8080",
+		ForwardedProto: "http",
+	}
+	if got != want {
+		t.Errorf("backend received %+v, want %+v", got, want)
+	}
+}
+
+func TestTableReloadsOnMiss(t *testing.T) {
+	var loads atomic.Int32
+	routes := map[string]*url.URL{}
+	table := &Table{
+		TTL: time.Hour,
+		Load: func(context.Context) (map[string]*url.URL, error) {
+			loads.Add(1)
+			return maps.Clone(routes), nil
+		},
+	}
+	ctx := context.Background()
+
+	if _, err := table.Resolve(ctx, "api"); !errors.Is(err, ErrNoRoute) {
+		t.Fatalf("Resolve before the route exists: err = %v, want ErrNoRoute", err)
+	}
+
+	// A miss within a second of the last load doesn't reload.
+	routes["api"] = &url.URL{Scheme: "http", Host: "127.0.0.1:1"}
+	if _, err := table.Resolve(ctx, "api"); !errors.Is(err, ErrNoRoute) {
+		t.Fatalf("Resolve right after a load: err = %v, want ErrNoRoute", err)
+	}
+	if n := loads.Load(); n != 1 {
+		t.Errorf("loads = %d, want 1", n)
+	}
+
+	table.mu.Lock()
+	table.loadedAt = table.loadedAt.Add(-2 * time.Second)
+	table.mu.Unlock()
+	u, err := table.Resolve(ctx, "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Host != "127.0.0.1:1" {
+		t.Errorf("Resolve = %v, want the new route", u)
+	}
+}
+
+func TestTableSharesLoads(t *testing.T) {
+	var loads atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	table := &Table{
+		TTL: time.Hour,
+		Load: func(context.Context) (map[string]*url.URL, error) {
+			if loads.Add(1) == 1 {
+				close(started)
+			}
+			<-release
+			return map[string]*url.URL{"api": {Scheme: "http", Host: "127.0.0.1:1"}}, nil
+		},
+	}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			if _, err := table.Resolve(context.Background(), "api"); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	<-started
+
+	// The table isn't held during the load: a caller that gives up waiting
+	// returns right away.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := table.Resolve(ctx, "api"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Resolve with a cancelled context: err = %v, want context.Canceled", err)
+	}
+
+	close(release)
+	wg.Wait()
+
+	if n := loads.Load(); n != 1 {
+		t.Errorf("loads = %d, want 1", n)
+	}
+}
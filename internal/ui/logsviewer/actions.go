@@ -0,0 +1,152 @@
+package logsviewer
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// serviceAction is something the viewer can do to the service of a tab.
+type serviceAction struct {
+	name string // e.g. "Restart"
+	verb string // e.g. "Restarting"
+	done string // e.g. "Restarted"
+	run  func(Backend, string) error
+}
+
+var (
+	restartAction = serviceAction{"Restart", "Restarting", "Restarted", Backend.Restart}
+	stopAction    = serviceAction{"Stop", "Stopping", "Stopped", Backend.Stop}
+	startAction   = serviceAction{"Start", "Starting", "Started", Backend.Start}
+)
+
+// actionDoneMsg signals that a service action has finished.
+type actionDoneMsg struct {
+	action serviceAction
+	tab    string
+	err    error
+}
+
+// confirmation is a service action awaiting a y/N answer.
+type confirmation struct {
+	action serviceAction
+	tab    string
+}
+
+func (c confirmation) pending() bool {
+	return c.tab != ""
+}
+
+// shellReadyMsg delivers the shell to run for a tab.
+type shellReadyMsg struct {
+	tab string
+	cmd *exec.Cmd
+	err error
+}
+
+// shellExitedMsg signals that the shell has exited and the viewer is back.
+type shellExitedMsg struct {
+	tab string
+	err error
+}
+
+// serviceTab returns the name of the active tab, or reports in the status
+// line that actions need a service tab.
+func (m *Model) serviceTab() (string, bool) {
+	t := &m.tabs[m.active]
+	if t.merged {
+		m.status = "Switch to the tab of a service first"
+		return "", false
+	}
+	return t.name, true
+}
+
+// runAction starts a service action on the active tab in the background.
+// Its logs carry on in the tab, re-attached by Options.Watch if the
+// container is replaced.
+func (m *Model) runAction(action serviceAction) tea.Cmd {
+	name, ok := m.serviceTab()
+	if !ok {
+		return nil
+	}
+	return m.runActionOn(action, name)
+}
+
+// confirmAction asks whether to run a service action on the active tab.
+func (m *Model) confirmAction(action serviceAction) {
+	if name, ok := m.serviceTab(); ok {
+		m.confirm = confirmation{action: action, tab: name}
+	}
+}
+
+// updateConfirm runs the action awaiting confirmation on "y", and cancels it
+// on any other key.
+func (m *Model) updateConfirm(msg tea.KeyMsg) tea.Cmd {
+	c := m.confirm
+	m.confirm = confirmation{}
+	if msg.String() != "y" && msg.String() != "Y" {
+		m.status = "Cancelled"
+		return nil
+	}
+	return m.runActionOn(c.action, c.tab)
+}
+
+// confirmStatus renders the question for the action awaiting confirmation.
+func (m *Model) confirmStatus() string {
+	return titleStyle.Render(fmt.Sprintf("%s %s? [y/N]", m.confirm.action.name, m.confirm.tab))
+}
+
+// runActionOn runs a service action on the named tab in the background.
+func (m *Model) runActionOn(action serviceAction, name string) tea.Cmd {
+	m.status = fmt.Sprintf("%s %s…", action.verb, name)
+	backend := m.backend
+	return func() tea.Msg {
+		return actionDoneMsg{action: action, tab: name, err: action.run(backend, name)}
+	}
+}
+
+// actionDone reports the outcome of a service action.
+func (m *Model) actionDone(msg actionDoneMsg) {
+	if msg.err != nil {
+		m.status = fmt.Sprintf("Error: %v", msg.err)
+		return
+	}
+	m.status = fmt.Sprintf("%s %s", msg.action.done, msg.tab)
+}
+
+// openShell prepares a shell for the service of the active tab in the
+// background, which can mean starting the service.
+func (m *Model) openShell() tea.Cmd {
+	name, ok := m.serviceTab()
+	if !ok {
+		return nil
+	}
+	m.status = fmt.Sprintf("Opening a shell in %s…", name)
+	backend := m.backend
+	return func() tea.Msg {
+		cmd, err := backend.ShellCmd(name)
+		return shellReadyMsg{tab: name, cmd: cmd, err: err}
+	}
+}
+
+// shellReady runs the shell, suspending the viewer until it exits.
+func (m *Model) shellReady(msg shellReadyMsg) tea.Cmd {
+	if msg.err != nil {
+		m.status = fmt.Sprintf("Error: %v", msg.err)
+		return nil
+	}
+	m.status = ""
+	return tea.ExecProcess(msg.cmd, func(err error) tea.Msg {
+		return shellExitedMsg{tab: msg.tab, err: err}
+	})
+}
+
+// shellExited reports if the shell failed. Exit statuses are left out, since
+// a shell exits with that of its last command.
+func (m *Model) shellExited(msg shellExitedMsg) {
+	if _, ok := errors.AsType[*exec.ExitError](msg.err); msg.err != nil && !ok {
+		m.status = fmt.Sprintf("Shell in %s exited: %v", msg.tab, msg.err)
+	}
+}
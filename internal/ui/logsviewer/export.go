@@ -0,0 +1,231 @@
+package logsviewer
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- Styles ----------------------------------------------------------------
+
+var selectionStyle = lipgloss.NewStyle().
+	Background(lipgloss.Color("238"))
+
+// selection is a range of shown lines in the active tab, for copying.
+type selection struct {
+	active bool
+	anchor int // where the selection started
+	cursor int // the line moved with the arrow keys
+}
+
+// bounds returns the first and last selected line.
+func (s selection) bounds() (int, int) {
+	return min(s.anchor, s.cursor), max(s.anchor, s.cursor)
+}
+
+// saveEditor holds the state of the save-to-file input.
+type saveEditor struct {
+	input   textinput.Model
+	editing bool
+	matches bool // save only lines matching the search
+}
+
+func newSaveEditor() saveEditor {
+	input := textinput.New()
+	input.Prompt = titleStyle.Render("save to: ")
+	return saveEditor{input: input}
+}
+
+// exportText returns a line as plain text for saving or copying, labeled
+// with its tab in the "All" tab, and with its timestamp if shown.
+func (m *Model) exportText(t *tabData, l logLine) string {
+	text := m.lineText(l)
+	if t.merged {
+		text = m.tabs[l.src].plainLabel + text
+	}
+	return m.gutterText(l) + text
+}
+
+// startSelection enters selection mode with the cursor on the current line.
+func (m *Model) startSelection() {
+	tab := &m.tabs[m.active]
+	if tab.shown.Len() == 0 {
+		return
+	}
+	line := m.currentLine()
+	tab.follow = false
+	m.selection = selection{active: true, anchor: line, cursor: line}
+	m.syncTab(m.active)
+}
+
+// currentLine is the line of the current search match, or else the last
+// line on screen.
+func (m *Model) currentLine() int {
+	tab := &m.tabs[m.active]
+	if tab.match >= 0 {
+		return tab.matches[tab.match]
+	}
+	return max(tab.bottomLine(), tab.shown.First())
+}
+
+// updateSelection handles keys in selection mode.
+func (m *Model) updateSelection(msg tea.KeyMsg) {
+	tab := &m.tabs[m.active]
+	s := &m.selection
+	switch msg.String() {
+	case "esc", "V":
+		s.active = false
+	case "y", "enter":
+		first, last := s.bounds()
+		m.copyLines(tab, first, last+1)
+		s.active = false
+	case "up", "k":
+		s.cursor--
+	case "down", "j":
+		s.cursor++
+	case "pgup":
+		s.cursor -= tab.view.height
+	case "pgdown":
+		s.cursor += tab.view.height
+	case "g", "home":
+		s.cursor = tab.shown.First()
+	case "G", "end":
+		s.cursor = tab.shown.End() - 1
+	}
+	s.cursor = max(min(s.cursor, tab.shown.End()-1), tab.shown.First())
+	tab.reveal(s.cursor)
+}
+
+// clamp keeps the selection within the shown lines after those before first
+// were dropped.
+func (s *selection) clamp(first int) {
+	s.anchor = max(s.anchor, first)
+	s.cursor = max(s.cursor, first)
+}
+
+// selected reports whether line seq of t is selected.
+func (m *Model) selected(t *tabData, seq int) bool {
+	if !m.selection.active || t != &m.tabs[m.active] {
+		return false
+	}
+	first, last := m.selection.bounds()
+	return seq >= first && seq <= last
+}
+
+// copyLines copies the shown lines of t from first up to end to the
+// clipboard using OSC 52, which works in most terminals, including over SSH.
+func (m *Model) copyLines(t *tabData, first, end int) {
+	texts := make([]string, 0, end-first)
+	for seq := first; seq < end; seq++ {
+		texts = append(texts, m.exportText(t, t.shown.At(seq)))
+	}
+
+	seq := osc52.New(strings.Join(texts, "\n"))
+	if os.Getenv("TMUX") != "" {
+		seq = seq.Tmux()
+	} else if strings.HasPrefix(os.Getenv("TERM"), "screen") {
+		seq = seq.Screen()
+	}
+	// Bubble Tea owns stdout, so write the sequence to the terminal via
+	// stderr.
+	if _, err := seq.WriteTo(os.Stderr); err != nil {
+		m.status = fmt.Sprintf("Copy failed: %v", err)
+		return
+	}
+	m.status = fmt.Sprintf("Copied %d line(s) to the clipboard", len(texts))
+}
+
+// copyCurrentLine copies the current line without entering selection mode.
+func (m *Model) copyCurrentLine() {
+	tab := &m.tabs[m.active]
+	if tab.shown.Len() == 0 {
+		return
+	}
+	seq := m.currentLine()
+	m.copyLines(tab, seq, seq+1)
+}
+
+// startSave opens the save input, prefilled with a file name for the tab.
+func (m *Model) startSave() tea.Cmd {
+	tab := &m.tabs[m.active]
+	name := strings.NewReplacer("/", "_", " ", "_").Replace(strings.ToLower(tab.name))
+	se := &m.saveEditor
+	se.editing = true
+	se.matches = false
+	se.input.SetValue(fmt.Sprintf("ifrit-%s-%s.log", name, time.Now().Format("20060102-150405")))
+	se.input.CursorEnd()
+	return se.input.Focus()
+}
+
+// updateSave handles keys while the file name is being typed.
+func (m *Model) updateSave(msg tea.KeyMsg) tea.Cmd {
+	se := &m.saveEditor
+	switch msg.String() {
+	case "enter":
+		se.editing = false
+		se.input.Blur()
+		m.save(se.input.Value(), se.matches)
+		return nil
+	case "esc", "ctrl+c":
+		se.editing = false
+		se.input.Blur()
+		return nil
+	case "tab":
+		se.matches = !se.matches && m.search.active()
+		return nil
+	}
+	var cmd tea.Cmd
+	se.input, cmd = se.input.Update(msg)
+	return cmd
+}
+
+// save writes the shown lines of the active tab, or only the search
+// matches, to path.
+func (m *Model) save(path string, matches bool) {
+	if path == "" {
+		return
+	}
+	tab := &m.tabs[m.active]
+
+	var b strings.Builder
+	count := 0
+	write := func(l logLine) {
+		b.WriteString(m.exportText(tab, l))
+		b.WriteByte('\n')
+		count++
+	}
+	if matches {
+		for _, seq := range tab.matches {
+			write(tab.shown.At(seq))
+		}
+	} else {
+		for seq := tab.shown.First(); seq < tab.shown.End(); seq++ {
+			write(tab.shown.At(seq))
+		}
+	}
+
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		m.status = fmt.Sprintf("Save failed: %v", err)
+		return
+	}
+	m.status = fmt.Sprintf("Saved %d line(s) to %s", count, path)
+}
+
+// saveStatus renders the save input for the help line.
+func (m *Model) saveStatus() string {
+	scope := "all shown lines"
+	if m.saveEditor.matches {
+		scope = "search matches"
+	}
+	keys := "enter: save  esc: cancel"
+	if m.search.active() {
+		keys += "  tab: all/matches"
+	}
+	return m.saveEditor.input.View() + "  [" + scope + "]  " + helpStyle.Render(keys)
+}
@@ -0,0 +1,245 @@
+package logsviewer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- Styles ----------------------------------------------------------------
+
+const filterColor = lipgloss.Color("156")
+
+var filterStyle = lipgloss.NewStyle().
+	Foreground(filterColor)
+
+// Filter restricts which log lines are shown. A line is shown if it matches
+// any include pattern (or there are none) and no exclude pattern.
+type Filter struct {
+	Include []*regexp.Regexp
+	Exclude []*regexp.Regexp
+}
+
+// NewFilter compiles include and exclude patterns. Patterns are regular
+// expressions, matched case-insensitively unless they contain upper case.
+func NewFilter(include, exclude []string) (Filter, error) {
+	var f Filter
+	for _, p := range include {
+		re, err := compileQuery(p, true)
+		if err != nil {
+			return Filter{}, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+		if re != nil {
+			f.Include = append(f.Include, re)
+		}
+	}
+	for _, p := range exclude {
+		re, err := compileQuery(p, true)
+		if err != nil {
+			return Filter{}, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+		if re != nil {
+			f.Exclude = append(f.Exclude, re)
+		}
+	}
+	return f, nil
+}
+
+// ParseFilter parses the filter syntax used in the viewer: space-separated
+// patterns, where patterns starting with "!" exclude lines.
+func ParseFilter(expr string) (Filter, error) {
+	var include, exclude []string
+	for _, term := range strings.Fields(expr) {
+		if p, ok := strings.CutPrefix(term, "!"); ok {
+			exclude = append(exclude, p)
+		} else {
+			include = append(include, term)
+		}
+	}
+	return NewFilter(include, exclude)
+}
+
+// Empty reports whether the filter shows every line.
+func (f Filter) Empty() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0
+}
+
+// Match reports whether line passes the filter.
+func (f Filter) Match(line string) bool {
+	for _, re := range f.Exclude {
+		if re.MatchString(line) {
+			return false
+		}
+	}
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, re := range f.Include {
+		if re.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the filter in the syntax accepted by ParseFilter.
+func (f Filter) String() string {
+	var terms []string
+	for _, re := range f.Include {
+		terms = append(terms, trimCaseFlag(re.String()))
+	}
+	for _, re := range f.Exclude {
+		terms = append(terms, "!"+trimCaseFlag(re.String()))
+	}
+	return strings.Join(terms, " ")
+}
+
+// trimCaseFlag removes the flag compileQuery adds for case-insensitivity.
+func trimCaseFlag(expr string) string {
+	return strings.TrimPrefix(expr, "(?i)")
+}
+
+// filterEditor holds the state of the filter input.
+type filterEditor struct {
+	input   textinput.Model
+	editing bool
+	global  bool // the filter being edited applies to all tabs
+	err     error
+
+	// The filters before editing started, restored on cancel.
+	prevGlobal Filter
+	prevTab    Filter
+}
+
+func newFilterEditor() filterEditor {
+	input := textinput.New()
+	input.Prompt = filterStyle.Render("filter: ")
+	input.Placeholder = "ERROR !healthz"
+	return filterEditor{input: input}
+}
+
+// passes reports whether a line passes the level filter, the global and the
+// tab's filter, and, in the "All" tab, comes from a tab that isn't muted.
+func (m *Model) passes(t *tabData, line logLine) bool {
+	if t.merged && m.tabs[line.src].muted {
+		return false
+	}
+	if !m.passesLevel(line) {
+		return false
+	}
+	return m.filter.Match(line.text) && t.filter.Match(line.text)
+}
+
+// refilter rebuilds the shown lines of a tab from its buffer.
+func (m *Model) refilter(t *tabData) {
+	// Keep the window as far from the oldest line as it was.
+	offset := t.view.top - t.shown.First()
+	t.shown.Reset()
+	for seq := t.lines.First(); seq < t.lines.End(); seq++ {
+		if line := t.lines.At(seq); m.passes(t, line) {
+			t.shown.Push(line)
+		}
+	}
+	t.view.top = t.shown.First() + offset
+	m.findMatches(t)
+}
+
+// startFilter opens the filter input for the active tab, or for all tabs if
+// a global filter is set.
+func (m *Model) startFilter() tea.Cmd {
+	tab := &m.tabs[m.active]
+	fe := &m.filterEditor
+	fe.editing = true
+	fe.err = nil
+	fe.prevGlobal = m.filter
+	fe.prevTab = tab.filter
+	fe.global = !m.filter.Empty() && tab.filter.Empty()
+	if fe.global {
+		fe.input.SetValue(m.filter.String())
+	} else {
+		fe.input.SetValue(tab.filter.String())
+	}
+	fe.input.CursorEnd()
+	return fe.input.Focus()
+}
+
+// updateFilter handles keys while the filter is being typed. The filter is
+// applied as it is typed.
+func (m *Model) updateFilter(msg tea.KeyMsg) tea.Cmd {
+	fe := &m.filterEditor
+	switch msg.String() {
+	case "enter":
+		fe.editing = false
+		fe.input.Blur()
+		return nil
+	case "esc", "ctrl+c":
+		fe.editing = false
+		fe.input.Blur()
+		m.filter = fe.prevGlobal
+		m.tabs[m.active].filter = fe.prevTab
+		m.applyFilters()
+		return nil
+	case "tab":
+		fe.global = !fe.global
+		// Move the filter over to the new scope.
+		if fe.global {
+			m.tabs[m.active].filter = fe.prevTab
+		} else {
+			m.filter = fe.prevGlobal
+		}
+		m.setFilter()
+		return nil
+	}
+
+	prev := fe.input.Value()
+	var cmd tea.Cmd
+	fe.input, cmd = fe.input.Update(msg)
+	if fe.input.Value() != prev {
+		m.setFilter()
+	}
+	return cmd
+}
+
+// setFilter parses the input and applies it to the scope being edited. An
+// invalid pattern leaves the current filter in place.
+func (m *Model) setFilter() {
+	fe := &m.filterEditor
+	f, err := ParseFilter(fe.input.Value())
+	fe.err = err
+	if err != nil {
+		return
+	}
+	if fe.global {
+		m.filter = f
+	} else {
+		m.tabs[m.active].filter = f
+	}
+	m.applyFilters()
+}
+
+// applyFilters rebuilds the shown lines of every tab.
+func (m *Model) applyFilters() {
+	for i := range m.tabs {
+		m.refilter(&m.tabs[i])
+	}
+	m.syncViewport()
+}
+
+// filterStatus renders the filter input for the help line.
+func (m *Model) filterStatus() string {
+	fe := &m.filterEditor
+	scope := "this tab"
+	if fe.global {
+		scope = "all tabs"
+	}
+	parts := []string{fe.input.View(), "[" + scope + "]"}
+	if fe.err != nil {
+		parts = append(parts, searchErrorStyle.Render("invalid regex"))
+	}
+	parts = append(parts, helpStyle.Render("!: exclude  enter: done  esc: cancel  tab: scope"))
+	return strings.Join(parts, "  ")
+}
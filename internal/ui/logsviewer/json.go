@@ -0,0 +1,292 @@
+package logsviewer
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Well-known keys of the common JSON loggers (zap, zerolog, slog, logrus,
+// pino, bunyan, logstash).
+var (
+	timeKeys    = []string{"time", "ts", "timestamp", "@timestamp"}
+	levelKeys   = []string{"level", "lvl", "severity", "@l", "loglevel"}
+	messageKeys = []string{"msg", "message", "@m", "@message"}
+)
+
+// levelNames are the levels the level filter steps through, lowest first.
+var levelNames = []string{"debug", "info", "warn", "error", "fatal"}
+
+// levelColors color levels in pretty mode, by rank.
+var levelColors = []lipgloss.Color{
+	lipgloss.Color("245"), // debug
+	lipgloss.Color("117"), // info
+	lipgloss.Color("221"), // warn
+	lipgloss.Color("203"), // error
+	lipgloss.Color("197"), // fatal
+}
+
+var jsonKeyStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("241"))
+
+// jsonEntry is a log line holding a JSON object, split into its well-known
+// fields and the rest.
+type jsonEntry struct {
+	prefix  string // text before the object, e.g. compose's "api-1  | "
+	time    string
+	level   string
+	rank    int // index into levelNames, or -1 if unknown
+	message string
+	fields  map[string]string
+	keys    []string // sorted keys of fields
+}
+
+// parseJSONLine parses a line holding a JSON object, possibly after a
+// prefix. It returns nil for any other line.
+func parseJSONLine(text string) *jsonEntry {
+	start := strings.IndexByte(text, '{')
+	if start < 0 || !strings.HasSuffix(strings.TrimSpace(text), "}") {
+		return nil
+	}
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(text[start:]), &obj); err != nil {
+		return nil
+	}
+
+	e := &jsonEntry{prefix: text[:start], rank: -1, fields: map[string]string{}}
+	for k, v := range obj {
+		e.fields[k] = jsonValue(v)
+	}
+	if k, ok := takeKey(e.fields, timeKeys); ok {
+		e.time = formatTime(obj[k])
+	}
+	if k, ok := takeKey(e.fields, levelKeys); ok {
+		e.level, e.rank = parseLevel(obj[k])
+	}
+	if k, ok := takeKey(e.fields, messageKeys); ok {
+		e.message = e.fields[k]
+	}
+	for _, keys := range [][]string{timeKeys, levelKeys, messageKeys} {
+		if k, ok := takeKey(e.fields, keys); ok {
+			delete(e.fields, k)
+		}
+	}
+	e.keys = slices.Sorted(maps.Keys(e.fields))
+	return e
+}
+
+// takeKey returns the first of keys present in fields.
+func takeKey(fields map[string]string, keys []string) (string, bool) {
+	for _, k := range keys {
+		if _, ok := fields[k]; ok {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+// jsonValue renders a JSON value for display: strings unquoted, numbers
+// without exponents, and objects and arrays as compact JSON.
+func jsonValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+		return "null"
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// formatTime shortens an RFC 3339 or Unix (seconds or milliseconds)
+// timestamp to the local time of day, and leaves anything else as is.
+func formatTime(v any) string {
+	switch v := v.(type) {
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t.Local().Format("15:04:05.000")
+		}
+		return v
+	case float64:
+		if v > 1e12 {
+			v /= 1000
+		}
+		sec, frac := math.Modf(v)
+		return time.Unix(int64(sec), int64(frac*1e9)).Local().Format("15:04:05.000")
+	}
+	return jsonValue(v)
+}
+
+// parseLevel returns the display name and rank of a level, given as a name
+// or as a pino/bunyan number (10 trace ... 60 fatal).
+func parseLevel(v any) (string, int) {
+	if n, ok := v.(float64); ok {
+		rank := min(max(int(n)/10-2, 0), len(levelNames)-1)
+		return levelNames[rank], rank
+	}
+
+	name := strings.ToLower(jsonValue(v))
+	switch name {
+	case "trace", "debug", "dbg":
+		return name, 0
+	case "info", "information", "notice":
+		return name, 1
+	case "warn", "warning":
+		return name, 2
+	case "error", "err":
+		return name, 3
+	case "fatal", "panic", "critical", "crit", "alert", "emergency":
+		return name, 4
+	}
+	return name, -1
+}
+
+// columnWidths returns the widest value of each column among lines.
+func columnWidths(lines []logLine, columns []string) []int {
+	widths := make([]int, len(columns))
+	for _, l := range lines {
+		if l.entry == nil {
+			continue
+		}
+		for i, c := range columns {
+			widths[i] = max(widths[i], lipgloss.Width(l.entry.fields[c]))
+		}
+	}
+	return widths
+}
+
+// render renders the entry as "time level [columns] msg key=val…". With
+// columns set, only those fields are shown, padded to widths. Styled output
+// colors the level and keys.
+func (e *jsonEntry) render(columns []string, widths []int, styled bool) string {
+	var parts []string
+	if e.time != "" {
+		parts = append(parts, e.time)
+	}
+	if e.level != "" {
+		level := fmt.Sprintf("%-5s", strings.ToUpper(e.level))
+		if styled && e.rank >= 0 {
+			level = lipgloss.NewStyle().Foreground(levelColors[e.rank]).Bold(e.rank >= 2).Render(level)
+		}
+		parts = append(parts, level)
+	}
+	for i, c := range columns {
+		width := 0
+		if i < len(widths) {
+			width = widths[i]
+		}
+		parts = append(parts, fmt.Sprintf("%-*s", width, e.fields[c]))
+	}
+	if e.message != "" {
+		parts = append(parts, e.message)
+	}
+	if len(columns) == 0 {
+		for _, k := range e.keys {
+			key := k + "="
+			if styled {
+				key = jsonKeyStyle.Render(key)
+			}
+			parts = append(parts, key+e.fields[k])
+		}
+	}
+	return e.prefix + strings.Join(parts, " ")
+}
+
+// lineText returns the text of a line as displayed, without styling or
+// column padding, which is what searches match.
+func (m *Model) lineText(l logLine) string {
+	if m.pretty && l.entry != nil {
+		return l.entry.render(m.columns, nil, false)
+	}
+	return l.text
+}
+
+// passesLevel reports whether a line is at or above the minimum level.
+// Lines without a known level always pass.
+func (m *Model) passesLevel(l logLine) bool {
+	return m.minLevel < 0 || l.entry == nil || l.entry.rank < 0 || l.entry.rank >= m.minLevel
+}
+
+// togglePretty switches between pretty and raw JSON lines.
+func (m *Model) togglePretty() {
+	m.pretty = !m.pretty
+	m.applyFilters()
+}
+
+// cycleLevel raises the minimum level, wrapping around to showing all.
+func (m *Model) cycleLevel() {
+	m.minLevel++
+	if m.minLevel >= len(levelNames) {
+		m.minLevel = -1
+	}
+	m.applyFilters()
+}
+
+// levelLabel describes the level filter for the tab bar.
+func (m *Model) levelLabel() string {
+	return "≥ " + strings.ToUpper(levelNames[m.minLevel])
+}
+
+// columnEditor holds the state of the JSON columns input.
+type columnEditor struct {
+	input   textinput.Model
+	editing bool
+}
+
+func newColumnEditor() columnEditor {
+	input := textinput.New()
+	input.Prompt = titleStyle.Render("columns: ")
+	input.Placeholder = "request_id status"
+	return columnEditor{input: input}
+}
+
+// startColumns opens the columns input.
+func (m *Model) startColumns() tea.Cmd {
+	m.columnEditor.editing = true
+	m.columnEditor.input.SetValue(strings.Join(m.columns, " "))
+	m.columnEditor.input.CursorEnd()
+	return m.columnEditor.input.Focus()
+}
+
+// updateColumns handles keys while the columns are being typed.
+func (m *Model) updateColumns(msg tea.KeyMsg) tea.Cmd {
+	ce := &m.columnEditor
+	switch msg.String() {
+	case "enter":
+		ce.editing = false
+		ce.input.Blur()
+		m.columns = strings.FieldsFunc(ce.input.Value(), func(r rune) bool {
+			return r == ' ' || r == ','
+		})
+		m.pretty = true
+		m.applyFilters()
+		return nil
+	case "esc", "ctrl+c":
+		ce.editing = false
+		ce.input.Blur()
+		return nil
+	}
+	var cmd tea.Cmd
+	ce.input, cmd = ce.input.Update(msg)
+	return cmd
+}
+
+// columnStatus renders the columns input for the help line.
+func (m *Model) columnStatus() string {
+	return m.columnEditor.input.View() + "  " +
+		helpStyle.Render("JSON fields to show as columns, empty for all  enter: done  esc: cancel")
+}
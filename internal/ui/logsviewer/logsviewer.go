@@ -2,14 +2,17 @@ package logsviewer
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
+	"slices"
 	"strings"
+	"time"
 
-	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
 // maxLines is the maximum number of log lines kept per tab.
@@ -86,35 +89,60 @@ func unreadStyle(color lipgloss.Color) lipgloss.Style {
 
 // --- Messages ---------------------------------------------------------------
 
-// logLineMsg delivers a single log line from a background process.
-type logLineMsg struct {
-	tab  int
-	line string
+// logEvent is a line read from a background process, or the process exiting.
+type logEvent struct {
+	tab    int
+	line   string
+	exited bool
+	err    error
 }
 
-// processExitedMsg signals that a log-tailing process has exited.
-type processExitedMsg struct {
-	tab int
-	err error
-}
+// logBatchMsg delivers the log events that arrived during one frame, so that
+// a chatty container costs one update and render per frame rather than per
+// line.
+type logBatchMsg []logEvent
 
-// logLineAndContinue wraps a log line plus a Cmd to read the next one.
-type logLineAndContinue struct {
-	logLineMsg
-	next tea.Cmd
-}
+const (
+	// frameInterval is how long events are collected into one batch.
+	frameInterval = time.Second / 60
+
+	// maxBatch bounds the events handled in one update, so that the viewer
+	// stays responsive to keys while a backlog is drained.
+	maxBatch = 4096
+)
 
 // --- Model ------------------------------------------------------------------
 
+// logLine is a line in a tab's buffer, along with the tab it came from.
+type logLine struct {
+	text  string
+	src   int
+	time  time.Time  // from the log, or zero if it had no timestamp
+	entry *jsonEntry // parsed JSON, if the line holds an object
+}
+
 // tabData holds per-tab state.
 type tabData struct {
-	name      string
-	group     string         // project group for color assignment
-	color     lipgloss.Color // color derived from group
-	lines     []string
-	viewport  viewport.Model
-	follow    bool // auto-scroll to bottom
-	hasUnread bool // new lines arrived while tab was not active
+	name       string
+	group      string         // project group for color assignment
+	color      lipgloss.Color // color derived from group
+	lines      *ring[logLine]
+	shown      *ring[logLine] // lines passing the filters
+	filter     Filter         // filter for this tab only
+	merged     bool           // the "All" tab, interleaving every other tab
+	muted      bool           // left out of the "All" tab
+	label      string         // rendered name prefixing this tab's lines in "All"
+	plainLabel string         // label without styling
+	view       scroller
+	follow     bool      // auto-scroll to bottom
+	hasUnread  bool      // new lines arrived while tab was not active
+	matches    []int     // sequence numbers of shown lines matching the search
+	match      int       // current position in matches, or -1
+	state      string    // container state, e.g. "running", if known
+	tailing    bool      // the log process is running
+	exitedAt   time.Time // when the log process last exited
+	lastTime   time.Time // timestamp of the last line from the logs
+	reattach   bool      // re-attach once the log process has exited
 }
 
 // TabInfo describes a single tab to be created in the viewer.
@@ -125,24 +153,76 @@ type TabInfo struct {
 
 // Model is the top-level Bubble Tea model for the interactive logs viewer.
 type Model struct {
-	tabs     []tabData
-	active   int
-	width    int
-	height   int
-	ready    bool
-	cmds     []*exec.Cmd
-	readers  []*os.File // read-end of each pipe, kept for cleanup
-	quitting bool
+	tabs         []tabData
+	active       int   // the tab in the focused pane
+	panes        []int // tabs shown side by side, at least one
+	focus        int   // index into panes
+	stacked      bool  // panes are stacked instead of side by side
+	width        int
+	height       int
+	ready        bool
+	backend      Backend
+	colors       map[string]lipgloss.Color // color of each group
+	cmds         []*exec.Cmd
+	readers      []*os.File // read-end of each pipe, kept for cleanup
+	events       chan logEvent
+	done         chan struct{} // closed when the viewer quits
+	search       search
+	filter       Filter // filter for all tabs
+	filterEditor filterEditor
+	pretty       bool     // render JSON lines as "time level msg key=val"
+	columns      []string // JSON fields shown as columns, or all if empty
+	columnEditor columnEditor
+	minLevel     int  // index into levelNames, or -1 to show all levels
+	timestamps   bool // show the timestamp gutter
+	wrap         bool // wrap long lines instead of cutting them
+	selection    selection
+	saveEditor   saveEditor
+	status       string // result of the last action, shown until the next key
+	confirm      confirmation
+	quitting     bool
+}
+
+// Options configures the viewer.
+type Options struct {
+	// Filter is applied to all tabs initially.
+	Filter Filter
+
+	// Timestamps shows the timestamp gutter initially. Lines get their
+	// timestamps from the logs, as printed by "docker compose logs
+	// --timestamps".
+	Timestamps bool
+
+	// Watch, if set, runs while the viewer is open and reports changes to
+	// the tabs by calling update, which adds tabs that don't exist yet.
+	Watch func(ctx context.Context, update func(TabUpdate)) error
 }
 
-// CmdBuilder is a function that returns an *exec.Cmd for tailing logs of a
-// given tab. The viewer calls this once per tab at startup. The argument is
-// the tab's Name from TabInfo.
-type CmdBuilder func(tabName string) (*exec.Cmd, error)
+// Backend follows and acts on the services behind the tabs, which keeps the
+// viewer independent of Docker. Tabs are identified by their Name from
+// TabInfo.
+type Backend interface {
+	// LogsCmd returns an *exec.Cmd for tailing logs of a tab. The viewer
+	// calls this once per tab at startup, with a zero since, and again when
+	// a tab is re-attached, with the time just after its last timestamped
+	// line (or when its logs stopped, if none had a timestamp).
+	LogsCmd(tabName string, since time.Time) (*exec.Cmd, error)
+
+	// Restart, Stop and Start act on the service of a tab. They may take a
+	// while, and are called outside of the UI loop.
+	Restart(tabName string) error
+	Stop(tabName string) error
+	Start(tabName string) error
+
+	// ShellCmd prepares the service of a tab and returns an interactive
+	// shell in it, which the viewer runs with the terminal to itself. It is
+	// called outside of the UI loop.
+	ShellCmd(tabName string) (*exec.Cmd, error)
+}
 
 // New creates a new Model. It does NOT start the background processes yet –
 // that happens in Init().
-func New(tabInfos []TabInfo, builder CmdBuilder) (*Model, error) {
+func New(tabInfos []TabInfo, backend Backend, opts Options) (*Model, error) {
 	// Assign a color to each unique group.
 	groupColorMap := make(map[string]lipgloss.Color)
 	colorIdx := 0
@@ -153,219 +233,473 @@ func New(tabInfos []TabInfo, builder CmdBuilder) (*Model, error) {
 		}
 	}
 
+	// With several tabs, the first one interleaves all the others.
+	offset := 0
+	if len(tabInfos) > 1 {
+		offset = 1
+	}
+
 	m := &Model{
-		tabs:    make([]tabData, len(tabInfos)),
-		cmds:    make([]*exec.Cmd, len(tabInfos)),
-		readers: make([]*os.File, len(tabInfos)),
+		backend:      backend,
+		colors:       groupColorMap,
+		tabs:         make([]tabData, len(tabInfos)+offset),
+		cmds:         make([]*exec.Cmd, len(tabInfos)+offset),
+		readers:      make([]*os.File, len(tabInfos)+offset),
+		events:       make(chan logEvent, maxBatch),
+		done:         make(chan struct{}),
+		panes:        []int{0},
+		search:       newSearch(),
+		filter:       opts.Filter,
+		filterEditor: newFilterEditor(),
+		pretty:       true,
+		columnEditor: newColumnEditor(),
+		minLevel:     -1,
+		timestamps:   opts.Timestamps,
+		saveEditor:   newSaveEditor(),
 	}
 
 	for i, ti := range tabInfos {
-		cmd, err := builder(ti.Name)
+		cmd, err := backend.LogsCmd(ti.Name, time.Time{})
 		if err != nil {
 			return nil, fmt.Errorf("failed to build log command for %s: %w", ti.Name, err)
 		}
-		m.cmds[i] = cmd
-		m.tabs[i] = tabData{
-			name:   ti.Name,
-			group:  ti.Group,
-			color:  groupColorMap[ti.Group],
-			lines:  []string{},
-			follow: true,
-		}
+		m.cmds[i+offset] = cmd
+		m.tabs[i+offset] = newTab(ti, groupColorMap[ti.Group])
+		m.tabs[i+offset].tailing = true
+	}
+	if offset > 0 {
+		m.tabs[0] = newMergedTab()
+		m.setLabels()
 	}
 
 	return m, nil
 }
 
-// Init starts background log-tailing goroutines for every tab.
+// newTab creates an empty tab that follows its lines.
+func newTab(ti TabInfo, color lipgloss.Color) tabData {
+	return tabData{
+		name:   ti.Name,
+		group:  ti.Group,
+		color:  color,
+		lines:  newRing[logLine](maxLines),
+		shown:  newRing[logLine](maxLines),
+		follow: true,
+		match:  -1,
+	}
+}
+
+// Init starts the background log-tailing processes for every tab.
 func (m *Model) Init() tea.Cmd {
-	cmds := make([]tea.Cmd, 0, len(m.cmds))
 	for i, cmd := range m.cmds {
-		cmds = append(cmds, m.tailLogs(i, cmd))
+		if cmd != nil {
+			m.tailLogs(i, cmd)
+		}
 	}
-	return tea.Batch(cmds...)
+	return m.waitForEvents()
 }
 
-// tailLogs starts the process and returns a tea.Cmd that streams lines into
-// the Bubble Tea runtime via messages.
+// tailLogs starts the process and a goroutine that sends its lines to the
+// events channel.
 //
 // We use os.Pipe so that both stdout and stderr write to the same pipe writer.
 // This avoids the io.MultiReader pitfall where stderr is never drained while
 // stdout is still streaming, which can deadlock the child process.
-func (m *Model) tailLogs(tab int, cmd *exec.Cmd) tea.Cmd {
-	return func() tea.Msg {
-		pr, pw, err := os.Pipe()
-		if err != nil {
-			return processExitedMsg{tab: tab, err: fmt.Errorf("pipe: %w", err)}
-		}
-
-		cmd.Stdout = pw
-		cmd.Stderr = pw
+func (m *Model) tailLogs(tab int, cmd *exec.Cmd) {
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		go m.send(logEvent{tab: tab, exited: true, err: fmt.Errorf("pipe: %w", err)})
+		return
+	}
 
-		if err := cmd.Start(); err != nil {
-			pw.Close()
-			pr.Close()
-			return processExitedMsg{tab: tab, err: err}
-		}
+	cmd.Stdout = pw
+	cmd.Stderr = pw
 
-		// Close the write-end in the parent process. The child holds its
-		// own copy of the fd. When the child exits, the last writer goes
-		// away and reads on pr will see EOF.
+	if err := cmd.Start(); err != nil {
 		pw.Close()
+		pr.Close()
+		go m.send(logEvent{tab: tab, exited: true, err: err})
+		return
+	}
+
+	// Close the write-end in the parent process. The child holds its
+	// own copy of the fd. When the child exits, the last writer goes
+	// away and reads on pr will see EOF.
+	pw.Close()
 
-		// Store the read-end so cleanup can close it if needed.
-		m.readers[tab] = pr
+	// Store the read-end so cleanup can close it if needed.
+	m.readers[tab] = pr
 
+	go func() {
 		scanner := bufio.NewScanner(pr)
 		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
-
-		if scanner.Scan() {
-			line := scanner.Text()
-			return logLineAndContinue{
-				logLineMsg: logLineMsg{tab: tab, line: line},
-				next:       continueScanning(tab, scanner, cmd, pr),
+		for scanner.Scan() {
+			if !m.send(logEvent{tab: tab, line: scanner.Text()}) {
+				break
 			}
 		}
-
-		// Nothing read at all — process probably exited immediately.
 		waitErr := cmd.Wait()
 		pr.Close()
-		return processExitedMsg{tab: tab, err: waitErr}
+		m.send(logEvent{tab: tab, exited: true, err: waitErr})
+	}()
+}
+
+// send delivers an event to the viewer. It reports false once the viewer
+// has quit.
+func (m *Model) send(e logEvent) bool {
+	select {
+	case m.events <- e:
+		return true
+	case <-m.done:
+		return false
 	}
 }
 
-// continueScanning returns a tea.Cmd that reads the next line from the scanner.
-func continueScanning(tab int, scanner *bufio.Scanner, cmd *exec.Cmd, pr *os.File) tea.Cmd {
+// waitForEvents returns a tea.Cmd that waits for the next log event, and
+// then collects the events arriving within the same frame into one batch.
+func (m *Model) waitForEvents() tea.Cmd {
 	return func() tea.Msg {
-		if scanner.Scan() {
-			line := scanner.Text()
-			return logLineAndContinue{
-				logLineMsg: logLineMsg{tab: tab, line: line},
-				next:       continueScanning(tab, scanner, cmd, pr),
+		var batch logBatchMsg
+		select {
+		case e := <-m.events:
+			batch = append(batch, e)
+		case <-m.done:
+			return nil
+		}
+
+		frame := time.NewTimer(frameInterval)
+		defer frame.Stop()
+		for len(batch) < maxBatch {
+			select {
+			case e := <-m.events:
+				batch = append(batch, e)
+			case <-frame.C:
+				return batch
+			case <-m.done:
+				return nil
 			}
 		}
-		waitErr := cmd.Wait()
-		pr.Close()
-		return processExitedMsg{tab: tab, err: waitErr}
+		return batch
 	}
 }
 
 // Update handles messages.
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	var cmds []tea.Cmd
-
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.search.editing {
+			return m, m.updateSearch(msg)
+		}
+		if m.filterEditor.editing {
+			return m, m.updateFilter(msg)
+		}
+		if m.columnEditor.editing {
+			return m, m.updateColumns(msg)
+		}
+		if m.saveEditor.editing {
+			return m, m.updateSave(msg)
+		}
+		if m.confirm.pending() && msg.String() != "ctrl+c" {
+			return m, m.updateConfirm(msg)
+		}
+		m.confirm = confirmation{}
+		m.status = ""
+		if m.selection.active && msg.String() != "ctrl+c" {
+			m.updateSelection(msg)
+			return m, nil
+		}
 		switch msg.String() {
-		case "ctrl+c", "q", "esc":
+		case "esc":
+			if m.search.active() || m.search.err != nil {
+				m.clearSearch()
+				break
+			}
 			m.quitting = true
 			m.killAll()
 			return m, tea.Quit
-		case "tab", "right", "l":
-			m.active = (m.active + 1) % len(m.tabs)
-			m.tabs[m.active].hasUnread = false
+		case "ctrl+c", "q":
+			m.quitting = true
+			m.killAll()
+			return m, tea.Quit
+		case "/":
+			return m, m.startSearch()
+		case "f":
+			return m, m.startFilter()
+		case "m":
+			m.toggleMuted(m.active)
+		case "p":
+			m.togglePretty()
+		case "c":
+			return m, m.startColumns()
+		case "L":
+			m.cycleLevel()
+		case "V":
+			m.startSelection()
+		case "y":
+			m.copyCurrentLine()
+		case "S":
+			return m, m.startSave()
+		case "t":
+			m.timestamps = !m.timestamps
 			m.syncViewport()
+		case "w":
+			m.wrap = !m.wrap
+			m.layout()
+		case "<":
+			m.tabs[m.active].panBy(-1)
+		case ">":
+			m.tabs[m.active].panBy(1)
+		case "r":
+			return m, m.runAction(restartAction)
+		case "D":
+			m.confirmAction(stopAction)
+		case "U":
+			return m, m.runAction(startAction)
+		case "e":
+			return m, m.openShell()
+		case "s":
+			m.splitPane()
+		case "x":
+			m.closePane()
+		case "o":
+			m.focusPane((m.focus + 1) % len(m.panes))
+		case "v":
+			m.stacked = !m.stacked
+			m.layout()
+		case "n":
+			m.nextMatch(1)
+		case "N":
+			m.nextMatch(-1)
+		case "tab", "right", "l":
+			m.setActive((m.active + 1) % len(m.tabs))
 		case "shift+tab", "left", "h":
-			m.active = (m.active - 1 + len(m.tabs)) % len(m.tabs)
-			m.tabs[m.active].hasUnread = false
-			m.syncViewport()
+			m.setActive((m.active - 1 + len(m.tabs)) % len(m.tabs))
 		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
 			idx := int(msg.String()[0]-'0') - 1
 			if idx < len(m.tabs) {
-				m.active = idx
-				m.tabs[m.active].hasUnread = false
-				m.syncViewport()
+				m.setActive(idx)
 			}
 		case "G", "end":
 			// Jump to bottom and re-enable follow.
-			tab := &m.tabs[m.active]
-			tab.follow = true
-			tab.viewport.GotoBottom()
+			m.tabs[m.active].gotoBottom()
 		case "g", "home":
 			// Jump to top and disable follow.
+			m.tabs[m.active].gotoTop()
+		case "up", "k":
+			m.tabs[m.active].scrollBy(-1)
+		case "down", "j":
+			m.tabs[m.active].scrollBy(1)
+		case "pgup", "b":
 			tab := &m.tabs[m.active]
-			tab.follow = false
-			tab.viewport.GotoTop()
-		default:
-			// Forward to viewport for scrolling (up/down/pgup/pgdn/etc).
+			tab.scrollBy(-tab.view.height)
+		case "pgdown", " ":
 			tab := &m.tabs[m.active]
-			var cmd tea.Cmd
-			tab.viewport, cmd = tab.viewport.Update(msg)
-			if cmd != nil {
-				cmds = append(cmds, cmd)
-			}
-			// If the user scrolled away from the bottom, disable follow.
-			tab.follow = tab.viewport.AtBottom()
+			tab.scrollBy(tab.view.height)
+		case "ctrl+u", "u":
+			tab := &m.tabs[m.active]
+			tab.scrollBy(-tab.view.height / 2)
+		case "ctrl+d", "d":
+			tab := &m.tabs[m.active]
+			tab.scrollBy(tab.view.height / 2)
 		}
 
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
 		m.ready = true
-		m.initViewports()
+		m.layout()
+
+	case logBatchMsg:
+		for _, e := range msg {
+			if e.exited {
+				m.processExited(e.tab, e.err)
+			} else {
+				m.appendLine(e.tab, e.line)
+			}
+		}
+		m.syncViewport()
+		return m, m.waitForEvents()
+
+	case tabUpdateMsg:
+		m.updateTab(TabUpdate(msg))
+		m.syncViewport()
 
-	case logLineAndContinue:
-		m.appendLine(msg.tab, msg.line)
-		cmds = append(cmds, msg.next)
+	case actionDoneMsg:
+		m.actionDone(msg)
 
-	case logLineMsg:
-		m.appendLine(msg.tab, msg.line)
+	case shellReadyMsg:
+		return m, m.shellReady(msg)
 
-	case processExitedMsg:
-		suffix := " [process exited]"
-		if msg.err != nil {
-			suffix = fmt.Sprintf(" [process exited: %v]", msg.err)
-		}
-		m.appendLine(msg.tab, suffix)
+	case shellExitedMsg:
+		m.shellExited(msg)
+
+	case watchFailedMsg:
+		m.status = fmt.Sprintf("Watching containers failed: %v", msg.err)
 	}
 
-	return m, tea.Batch(cmds...)
+	return m, nil
 }
 
-// appendLine adds a line to the tab and refreshes the viewport.
-func (m *Model) appendLine(tab int, line string) {
+// appendLine adds a line to the tab, and to the "All" tab if there is one.
+// A timestamp at the start of the line goes to the gutter.
+func (m *Model) appendLine(tab int, text string) {
 	if tab < 0 || tab >= len(m.tabs) {
 		return
 	}
+	ts, text := splitTimestamp(text)
+	if !ts.IsZero() {
+		m.tabs[tab].lastTime = ts
+	}
+	line := logLine{text: text, src: tab, time: ts, entry: parseJSONLine(text)}
+	m.addLine(tab, line)
+	if tab != 0 && m.tabs[0].merged {
+		m.addLine(0, line)
+	}
+}
+
+// addLine adds a line to a single tab.
+func (m *Model) addLine(tab int, line logLine) {
 	t := &m.tabs[tab]
-	t.lines = append(t.lines, line)
-	if len(t.lines) > maxLines {
-		// Trim oldest lines.
-		t.lines = t.lines[len(t.lines)-maxLines:]
+	if old, dropped := t.lines.Push(line); dropped && m.passes(t, old) {
+		// The oldest line was shown, so it goes from the shown lines too.
+		t.shown.DropFirst()
+		t.dropMatches()
+		if tab == m.active && m.selection.active {
+			m.selection.clamp(t.shown.First())
+		}
 	}
 
-	if tab == m.active {
-		m.syncViewport()
-	} else {
+	if !m.passes(t, line) {
+		return
+	}
+	if m.search.active() && m.search.re.MatchString(m.lineText(line)) {
+		t.matches = append(t.matches, t.shown.End())
+	}
+	t.shown.Push(line)
+
+	// Visible tabs are synced once the whole batch of lines is in.
+	if !slices.Contains(m.panes, tab) {
 		t.hasUnread = true
 	}
 }
 
-// syncViewport updates the active tab's viewport content.
-func (m *Model) syncViewport() {
-	if !m.ready {
-		return
+// setActive shows the given tab in the focused pane and marks it as read. If
+// another pane shows the tab, the two panes swap tabs.
+func (m *Model) setActive(idx int) {
+	if p := slices.Index(m.panes, idx); p >= 0 {
+		m.panes[p] = m.active
+	}
+	m.panes[m.focus] = idx
+	m.active = idx
+	m.tabs[m.active].hasUnread = false
+	m.layout()
+}
+
+// renderTab renders the shown lines of a tab that are in its window, cut or
+// padded to the window size. Only these lines are rendered, so drawing a
+// frame costs the same however many lines are buffered.
+func (m *Model) renderTab(t *tabData) string {
+	// With wrapping, the newest line sits at the bottom while following,
+	// even if the line above it is only partly visible.
+	anchored := m.wrap && t.atBottom()
+	first := t.view.top
+	if anchored {
+		first = max(t.shown.End()-t.view.height, t.shown.First())
 	}
-	tab := &m.tabs[m.active]
-	content := strings.Join(tab.lines, "\n")
-	tab.viewport.SetContent(content)
-	if tab.follow {
-		tab.viewport.GotoBottom()
+	end := min(first+t.view.height, t.shown.End())
+
+	// Columns are as wide as their widest value on screen.
+	var widths []int
+	if m.pretty && len(m.columns) > 0 {
+		visible := make([]logLine, 0, max(end-first, 0))
+		for seq := first; seq < end; seq++ {
+			visible = append(visible, t.shown.At(seq))
+		}
+		widths = columnWidths(visible, m.columns)
 	}
+
+	var rows []string
+	if anchored {
+		for seq := t.shown.End() - 1; seq >= t.shown.First() && len(rows) < t.view.height; seq-- {
+			rows = append(m.lineRows(t, seq, widths), rows...)
+		}
+		rows = rows[max(len(rows)-t.view.height, 0):]
+	} else {
+		for seq := t.view.top; seq < t.shown.End() && len(rows) < t.view.height; seq++ {
+			rows = append(rows, m.lineRows(t, seq, widths)...)
+		}
+		rows = rows[:min(len(rows), t.view.height)]
+	}
+
+	blank := strings.Repeat(" ", t.view.width)
+	for len(rows) < t.view.height {
+		rows = append(rows, blank)
+	}
+	return strings.Join(rows, "\n")
 }
 
-// initViewports (re-)initializes all viewports to the current terminal size.
-func (m *Model) initViewports() {
-	vpHeight := m.viewportHeight()
-	vpWidth := m.width
-	for i := range m.tabs {
-		m.tabs[i].viewport = viewport.New(vpWidth, vpHeight)
-		m.tabs[i].viewport.MouseWheelEnabled = false
-		content := strings.Join(m.tabs[i].lines, "\n")
-		m.tabs[i].viewport.SetContent(content)
-		if m.tabs[i].follow {
-			m.tabs[i].viewport.GotoBottom()
+// lineRows renders a shown line as the rows it takes on screen: wrapped, or
+// else scrolled horizontally and cut to the window width. The timestamp
+// gutter and, in the "All" tab, the label of the line's tab stay in place.
+func (m *Model) lineRows(t *tabData, seq int, widths []int) []string {
+	l := t.shown.At(seq)
+	prefix := m.gutter(l)
+	if t.merged {
+		prefix += m.tabs[l.src].label
+	}
+	text := expandTabs(m.renderLine(t, seq, l, widths))
+	indent := ansi.StringWidth(prefix)
+	width := max(t.view.width-indent, 1)
+
+	if !m.wrap {
+		return []string{fitLine(prefix+ansi.Cut(text, t.view.left, t.view.left+width), t.view.width)}
+	}
+	rows := strings.Split(ansi.Hardwrap(text, width, true), "\n")
+	for i := range rows {
+		if i == 0 {
+			rows[i] = fitLine(prefix+rows[i], t.view.width)
+		} else {
+			rows[i] = fitLine(strings.Repeat(" ", indent)+rows[i], t.view.width)
+		}
+	}
+	return rows
+}
+
+// renderLine renders the text of a shown line: JSON pretty-printed if
+// enabled, and search matches highlighted.
+func (m *Model) renderLine(t *tabData, seq int, l logLine, widths []int) string {
+	pretty := m.pretty && l.entry != nil
+	if m.selected(t, seq) {
+		return selectionStyle.Render(m.lineText(l))
+	}
+	if j, ok := slices.BinarySearch(t.matches, seq); ok && m.search.active() {
+		// Highlighting replaces styling, so matches are rendered unstyled.
+		text := l.text
+		if pretty {
+			text = l.entry.render(m.columns, widths, false)
 		}
+		return highlight(text, m.search.re, j == t.match)
+	}
+	if pretty {
+		return l.entry.render(m.columns, widths, true)
+	}
+	return l.text
+}
+
+// syncViewport keeps the window of every visible tab in place.
+func (m *Model) syncViewport() {
+	for _, tab := range m.panes {
+		m.syncTab(tab)
+	}
+}
+
+// syncTab keeps the window of a tab at the bottom if it follows, or else
+// within its shown lines.
+func (m *Model) syncTab(tab int) {
+	t := &m.tabs[tab]
+	if t.follow {
+		t.gotoBottom()
+	} else {
+		t.clamp()
 	}
 }
 
@@ -394,6 +728,15 @@ func (m *Model) View() string {
 		if i < 9 {
 			label = fmt.Sprintf("%d: %s", i+1, label)
 		}
+		if !t.filter.Empty() {
+			label += " " + filterStyle.Render("⧩ "+shorten(t.filter.String(), 20))
+		}
+		if t.muted {
+			label += " " + helpStyle.Render("⊘")
+		}
+		if t.state != "" {
+			label += " " + stateStyle(t.state).Render(t.state)
+		}
 		if i == m.active {
 			tabs = append(tabs, activeStyle(t.color).Render(label))
 		} else if t.hasUnread {
@@ -403,24 +746,60 @@ func (m *Model) View() string {
 			tabs = append(tabs, inactiveStyle(t.color).Render(label))
 		}
 	}
+	if !m.filter.Empty() {
+		label := filterStyle.Render("⧩ all: " + shorten(m.filter.String(), 30))
+		tabs = append(tabs, inactiveStyle(filterColor).Render(label))
+	}
+	if m.minLevel >= 0 {
+		label := filterStyle.Render("⧩ level " + m.levelLabel())
+		tabs = append(tabs, inactiveStyle(filterColor).Render(label))
+	}
 	tabBar := tabBarStyle.Width(m.width).Render(lipgloss.JoinHorizontal(lipgloss.Bottom, tabs...))
 
-	// --- Viewport ---
-	vp := m.tabs[m.active].viewport.View()
+	// --- Viewports ---
+	vp := m.panesView()
 
 	// --- Help ---
 	followIndicator := ""
 	if m.tabs[m.active].follow {
 		followIndicator = " │ " + titleStyle.Render("FOLLOWING")
 	}
-	help := helpStyle.Render("tab/←→: switch  ↑↓/pgup/pgdn: scroll  G: follow  g: top  esc/q: quit") + followIndicator
+	help := helpStyle.Render("←→: tab  /: search  f: filter  m: mute  s/o/x: panes  p/c/L: JSON  t/w: time/wrap  V/y: copy  S: save  r/D/U/e: service  q: quit") + followIndicator
+	if m.filterEditor.editing {
+		help = m.filterStatus()
+	} else if m.columnEditor.editing {
+		help = m.columnStatus()
+	} else if m.saveEditor.editing {
+		help = m.saveStatus()
+	} else if m.confirm.pending() {
+		help = m.confirmStatus()
+	} else if m.selection.active {
+		help = titleStyle.Render("SELECT") + "  " + helpStyle.Render("↑↓/pgup/pgdn: extend  y: copy  esc: cancel")
+	} else if m.status != "" {
+		help = titleStyle.Render(m.status) + followIndicator
+	} else if m.search.editing || m.search.active() || m.search.err != nil {
+		help = m.searchStatus() + followIndicator
+	}
 
 	return tabBar + "\n" + vp + "\n" + help
 }
 
+// shorten truncates s to at most n runes, marking the cut with an ellipsis.
+func shorten(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	if n < 1 {
+		return ""
+	}
+	return string(r[:n-1]) + "…"
+}
+
 // killAll kills all background log processes and closes pipe readers so that
 // any blocked scanner.Scan() calls unblock and return.
 func (m *Model) killAll() {
+	close(m.done)
 	for i, cmd := range m.cmds {
 		if cmd != nil && cmd.Process != nil {
 			_ = cmd.Process.Kill()
@@ -433,17 +812,29 @@ func (m *Model) killAll() {
 
 // Run is a convenience function that creates a Bubble Tea program and runs
 // the model. It blocks until the user quits.
-func Run(tabInfos []TabInfo, builder CmdBuilder) error {
+func Run(tabInfos []TabInfo, backend Backend, opts Options) error {
 	if len(tabInfos) == 0 {
 		return fmt.Errorf("no tabs to show logs for")
 	}
 
-	model, err := New(tabInfos, builder)
+	model, err := New(tabInfos, backend, opts)
 	if err != nil {
 		return err
 	}
 
 	p := tea.NewProgram(model, tea.WithAltScreen())
+	if opts.Watch != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			err := opts.Watch(ctx, func(u TabUpdate) {
+				p.Send(tabUpdateMsg(u))
+			})
+			if err != nil {
+				p.Send(watchFailedMsg{err: err})
+			}
+		}()
+	}
 	if _, err := p.Run(); err != nil {
 		return fmt.Errorf("TUI error: %w", err)
 	}
@@ -0,0 +1,156 @@
+package logsviewer
+
+import (
+	"fmt"
+	"os/exec"
+	"slices"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ringItems returns the items of r from oldest to newest.
+func ringItems(r *ring[int]) []int {
+	var items []int
+	for seq := r.First(); seq < r.End(); seq++ {
+		items = append(items, r.At(seq))
+	}
+	return items
+}
+
+func TestRingPushDropsOldest(t *testing.T) {
+	r := newRing[int](3)
+	for i := range 5 {
+		old, dropped := r.Push(i)
+		if wantDropped := i >= 3; dropped != wantDropped || (dropped && old != i-3) {
+			t.Errorf("Push(%d) = %d, %v; want %d, %v", i, old, dropped, i-3, wantDropped)
+		}
+	}
+	if got, want := ringItems(r), []int{2, 3, 4}; !slices.Equal(got, want) {
+		t.Errorf("items = %v, want %v", got, want)
+	}
+	if r.First() != 2 || r.End() != 5 {
+		t.Errorf("sequence numbers = [%d, %d), want [2, 5)", r.First(), r.End())
+	}
+}
+
+func TestRingWraparound(t *testing.T) {
+	// Dropping items before the ring is full leaves free slots at the
+	// front, which later pushes have to fill in order.
+	r := newRing[int](8)
+	var want []int
+	next := 0
+	steps := []struct {
+		push, drop int
+	}{
+		{push: 3},
+		{drop: 3}, // empty, with the oldest slot wrapped past the end
+		{push: 4},
+		{drop: 2},
+		{push: 5}, // fills the ring up to its capacity
+		{drop: 1},
+		{push: 6}, // drops the oldest items
+		{drop: 7},
+		{push: 2},
+	}
+	for i, step := range steps {
+		for range step.push {
+			r.Push(next)
+			want = append(want, next)
+			next++
+		}
+		want = want[max(len(want)-8, 0):]
+		for range step.drop {
+			r.DropFirst()
+			want = want[1:]
+		}
+
+		if got := ringItems(r); !slices.Equal(got, want) {
+			t.Fatalf("step %d: items = %v, want %v", i, got, want)
+		}
+		if r.Len() != len(want) || r.End() != next {
+			t.Fatalf("step %d: Len, End = %d, %d; want %d, %d", i, r.Len(), r.End(), len(want), next)
+		}
+	}
+}
+
+func TestRingReset(t *testing.T) {
+	r := newRing[int](4)
+	for i := range 6 {
+		r.Push(i)
+	}
+	r.Reset()
+	if r.Len() != 0 || r.First() != 6 || r.End() != 6 {
+		t.Fatalf("after Reset: Len, First, End = %d, %d, %d; want 0, 6, 6", r.Len(), r.First(), r.End())
+	}
+	r.Push(6)
+	if got := ringItems(r); !slices.Equal(got, []int{6}) {
+		t.Errorf("items = %v, want [6]", got)
+	}
+}
+
+// stubBackend is a Backend for tabs that are never tailed.
+type stubBackend struct{}
+
+func (stubBackend) LogsCmd(string, time.Time) (*exec.Cmd, error) { return exec.Command("true"), nil }
+func (stubBackend) Restart(string) error                         { return nil }
+func (stubBackend) Stop(string) error                            { return nil }
+func (stubBackend) Start(string) error                           { return nil }
+func (stubBackend) ShellCmd(string) (*exec.Cmd, error)           { return exec.Command("true"), nil }
+
+// benchBatch returns a frame's worth of log lines for tabs 1 and 2, a mix of
+// plain text, JSON and lines too long for the screen.
+func benchBatch(start, size int) logBatchMsg {
+	batch := make(logBatchMsg, size)
+	for i := range batch {
+		n := start + i
+		var line string
+		switch n % 3 {
+		case 0:
+			line = fmt.Sprintf("2026-10-18T10:00:00.%09dZ plain line %d", n, n)
+		case 1:
+			line = fmt.Sprintf(`{"time":"2026-10-18T10:00:00Z","level":"info","msg":"request %d","ms":%d}`, n, n%500)
+		default:
+			line = fmt.Sprintf("long line %d %0200d", n, n)
+		}
+		batch[i] = logEvent{tab: 1 + n%2, line: line}
+	}
+	return batch
+}
+
+// BenchmarkAppendAndRender measures a frame of a chatty viewer: a batch of
+// lines arriving in tabs whose buffers are full, followed by a render.
+func BenchmarkAppendAndRender(b *testing.B) {
+	for _, wrap := range []bool{false, true} {
+		b.Run(fmt.Sprintf("wrap=%v", wrap), func(b *testing.B) {
+			m, err := New([]TabInfo{
+				{Name: "backend/api", Group: "backend"},
+				{Name: "backend/worker", Group: "backend"},
+			}, stubBackend{}, Options{Timestamps: true})
+			if err != nil {
+				b.Fatal(err)
+			}
+			m.wrap = wrap
+			m.Update(tea.WindowSizeMsg{Width: 160, Height: 50})
+
+			// Fill every ring, including the "All" tab's.
+			const frame = 256
+			n := 0
+			for n < 2*maxLines {
+				m.Update(benchBatch(n, frame))
+				n += frame
+			}
+			if m.tabs[0].lines.Len() != maxLines {
+				b.Fatalf("All tab has %d lines, want %d", m.tabs[0].lines.Len(), maxLines)
+			}
+
+			b.ReportAllocs()
+			for b.Loop() {
+				m.Update(benchBatch(n, frame))
+				n += frame
+				_ = m.View()
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package logsviewer
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// mergedTabName is the name of the tab interleaving all other tabs.
+const mergedTabName = "All"
+
+// mergedColor is the color of the "All" tab, distinct from groupColors.
+const mergedColor = lipgloss.Color("252")
+
+func newMergedTab() tabData {
+	return tabData{
+		name:   mergedTabName,
+		color:  mergedColor,
+		lines:  newRing[logLine](maxLines),
+		shown:  newRing[logLine](maxLines),
+		merged: true,
+		follow: true,
+		match:  -1,
+	}
+}
+
+// setLabels renders the label prefixing each tab's lines in the "All" tab,
+// colored by group and padded so that the lines align, and its plain text
+// version for saving and copying.
+func (m *Model) setLabels() {
+	width := 0
+	for _, t := range m.tabs {
+		if !t.merged {
+			width = max(width, lipgloss.Width(t.name))
+		}
+	}
+	sep := helpStyle.Render(" │ ")
+	for i := range m.tabs {
+		t := &m.tabs[i]
+		if t.merged {
+			continue
+		}
+		t.label = lipgloss.NewStyle().Foreground(t.color).Render(fmt.Sprintf("%-*s", width, t.name)) + sep
+		t.plainLabel = fmt.Sprintf("%-*s | ", width, t.name)
+	}
+}
+
+// toggleMuted includes or excludes a tab's lines from the "All" tab.
+func (m *Model) toggleMuted(tab int) {
+	if m.tabs[tab].merged || !m.tabs[0].merged {
+		return
+	}
+	m.tabs[tab].muted = !m.tabs[tab].muted
+	m.refilter(&m.tabs[0])
+	m.syncViewport()
+}
@@ -0,0 +1,122 @@
+package logsviewer
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxPanes is the maximum number of tabs shown at the same time.
+const maxPanes = 4
+
+// splitPane adds a pane showing the next tab that isn't already visible,
+// and focuses it.
+func (m *Model) splitPane() {
+	if len(m.panes) >= maxPanes {
+		return
+	}
+	for step := 1; step < len(m.tabs); step++ {
+		tab := (m.active + step) % len(m.tabs)
+		if slices.Contains(m.panes, tab) {
+			continue
+		}
+		m.panes = append(m.panes, tab)
+		m.focusPane(len(m.panes) - 1)
+		return
+	}
+}
+
+// closePane removes the focused pane, unless it is the only one.
+func (m *Model) closePane() {
+	if len(m.panes) == 1 {
+		return
+	}
+	m.panes = slices.Delete(m.panes, m.focus, m.focus+1)
+	m.focusPane(min(m.focus, len(m.panes)-1))
+}
+
+// focusPane moves the focus, and with it keys and search, to a pane.
+func (m *Model) focusPane(p int) {
+	m.focus = p
+	m.active = m.panes[p]
+	m.tabs[m.active].hasUnread = false
+	m.layout()
+}
+
+// paneSize returns the viewport size of pane p. Each pane but a single one
+// has a header line, and side-by-side panes are separated by a column.
+func (m *Model) paneSize(p int) (int, int) {
+	n := len(m.panes)
+	w, h := m.width, m.viewportHeight()
+	if n == 1 {
+		return w, h
+	}
+	if m.stacked {
+		h -= n
+		size := h / n
+		if p == n-1 {
+			size = h - (n-1)*size
+		}
+		return w, max(size, 1)
+	}
+	w -= n - 1
+	size := w / n
+	if p == n-1 {
+		size = w - (n-1)*size
+	}
+	return max(size, 1), max(h-1, 1)
+}
+
+// layout sizes the viewports of the visible tabs to their panes.
+func (m *Model) layout() {
+	if !m.ready {
+		return
+	}
+	for p, tab := range m.panes {
+		view := &m.tabs[tab].view
+		view.width, view.height = m.paneSize(p)
+		view.rows = nil
+		if m.wrap {
+			view.rows = func(seq int) int {
+				return len(m.lineRows(&m.tabs[tab], seq, nil))
+			}
+		}
+	}
+	m.syncViewport()
+}
+
+// paneHeader renders the title line of pane p, highlighted if focused.
+func (m *Model) paneHeader(p int) string {
+	t := &m.tabs[m.panes[p]]
+	style := helpStyle
+	title := "  " + t.name
+	if p == m.focus {
+		style = lipgloss.NewStyle().Foreground(t.color).Bold(true)
+		title = "▶ " + t.name
+	}
+	if t.follow {
+		title += " (following)"
+	}
+	return style.Render(shorten(title, t.view.width))
+}
+
+// panesView renders the visible tabs, side by side or stacked.
+func (m *Model) panesView() string {
+	if len(m.panes) == 1 {
+		return m.renderTab(&m.tabs[m.active])
+	}
+
+	views := make([]string, 0, 2*len(m.panes)-1)
+	sep := strings.TrimSuffix(strings.Repeat(helpStyle.Render("│")+"\n", m.viewportHeight()), "\n")
+	for p, tab := range m.panes {
+		if p > 0 && !m.stacked {
+			views = append(views, sep)
+		}
+		views = append(views, m.paneHeader(p)+"\n"+m.renderTab(&m.tabs[tab]))
+	}
+	if m.stacked {
+		return lipgloss.JoinVertical(lipgloss.Left, views...)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, views...)
+}
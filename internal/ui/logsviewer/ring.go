@@ -0,0 +1,89 @@
+package logsviewer
+
+import "slices"
+
+// ring is a bounded FIFO buffer that drops its oldest item when full, so
+// that appending never copies the buffer. Items are addressed by sequence
+// number, which keeps increasing as items are pushed, so positions held
+// elsewhere (search matches, the scroll position) stay valid as old items
+// are dropped.
+type ring[T any] struct {
+	items []T
+	size  int // maximum number of items
+	start int // index in items of the oldest item
+	n     int // number of items
+	first int // sequence number of the oldest item
+}
+
+func newRing[T any](capacity int) *ring[T] {
+	// Grow up to capacity as needed, since most tabs never fill up.
+	return &ring[T]{items: make([]T, 0, min(capacity, 1024)), size: capacity}
+}
+
+// Push appends v, dropping and returning the oldest item if the ring is full.
+func (r *ring[T]) Push(v T) (T, bool) {
+	var dropped T
+	if r.n < r.size {
+		if r.n < len(r.items) {
+			r.items[(r.start+r.n)%len(r.items)] = v
+		} else {
+			if r.start != 0 {
+				// Items dropped before the ring filled up left it wrapped;
+				// unwrap it so that the new item goes after the newest.
+				r.items = slices.Concat(r.items[r.start:], r.items[:r.start])
+				r.start = 0
+			}
+			r.items = append(r.items, v)
+		}
+		r.n++
+		return dropped, false
+	}
+
+	dropped = r.items[r.start]
+	r.items[r.start] = v
+	r.start = (r.start + 1) % len(r.items)
+	r.first++
+	return dropped, true
+}
+
+// DropFirst removes the oldest item.
+func (r *ring[T]) DropFirst() {
+	if r.n == 0 {
+		return
+	}
+	var zero T
+	r.items[r.start] = zero
+	r.start = (r.start + 1) % len(r.items)
+	r.n--
+	r.first++
+}
+
+// Len returns the number of items.
+func (r *ring[T]) Len() int {
+	return r.n
+}
+
+// First returns the sequence number of the oldest item.
+func (r *ring[T]) First() int {
+	return r.first
+}
+
+// End returns the sequence number the next pushed item will get.
+func (r *ring[T]) End() int {
+	return r.first + r.n
+}
+
+// At returns the item with sequence number seq, which must be in
+// [First, End).
+func (r *ring[T]) At(seq int) T {
+	return r.items[(r.start+seq-r.first)%len(r.items)]
+}
+
+// Reset removes all items. Sequence numbers continue where they were.
+func (r *ring[T]) Reset() {
+	clear(r.items)
+	r.items = r.items[:0]
+	r.first += r.n
+	r.start = 0
+	r.n = 0
+}
@@ -0,0 +1,136 @@
+package logsviewer
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// scroller is the visible window of a tab. Only the lines inside it are
+// rendered, so the cost of drawing a frame doesn't grow with the buffer.
+type scroller struct {
+	width  int
+	height int
+	top    int // sequence number of the first visible shown line
+	left   int // columns scrolled to the right, if lines don't wrap
+
+	// rows returns the number of rows a line takes when lines wrap, or is
+	// nil if every line takes one row.
+	rows func(seq int) int
+}
+
+// maxTop returns the top line of the window when scrolled to the bottom.
+func (t *tabData) maxTop() int {
+	if t.view.rows == nil {
+		return max(t.shown.End()-t.view.height, t.shown.First())
+	}
+	top, used := t.shown.End(), 0
+	for top > t.shown.First() && used < t.view.height {
+		top--
+		used += t.view.rows(top)
+	}
+	return top
+}
+
+// clamp keeps the window within the shown lines of t.
+func (t *tabData) clamp() {
+	t.view.top = min(max(t.view.top, t.shown.First()), t.maxTop())
+}
+
+// scrollBy moves the window by n lines, and follows if it reaches the bottom.
+func (t *tabData) scrollBy(n int) {
+	t.view.top += n
+	t.clamp()
+	t.follow = t.atBottom()
+}
+
+// panBy scrolls long lines by half a window to the right (dir 1) or left
+// (dir -1).
+func (t *tabData) panBy(dir int) {
+	t.view.left = max(t.view.left+dir*max(t.view.width/2, 1), 0)
+}
+
+// gotoTop shows the oldest lines and stops following.
+func (t *tabData) gotoTop() {
+	t.view.top = t.shown.First()
+	t.follow = false
+}
+
+// gotoBottom shows the newest lines and follows.
+func (t *tabData) gotoBottom() {
+	t.view.top = t.maxTop()
+	t.follow = true
+}
+
+// atBottom reports whether the newest line is visible.
+func (t *tabData) atBottom() bool {
+	return t.view.top >= t.maxTop()
+}
+
+// center scrolls so that line seq is in the middle, and stops following.
+func (t *tabData) center(seq int) {
+	t.view.top = seq - t.view.height/2
+	if t.view.rows != nil {
+		// Take as many lines above seq as fill half the window.
+		used := t.view.rows(seq)
+		for t.view.top = seq; t.view.top > t.shown.First() && used < t.view.height/2; {
+			t.view.top--
+			used += t.view.rows(t.view.top)
+		}
+	}
+	t.clamp()
+	t.follow = false
+}
+
+// reveal scrolls as little as possible to make line seq visible.
+func (t *tabData) reveal(seq int) {
+	if seq < t.view.top {
+		t.view.top = seq
+	} else if seq > t.bottomLine() {
+		t.view.top = seq - t.view.height + 1
+		if t.view.rows != nil {
+			// Take as many lines above seq as fit entirely.
+			used := t.view.rows(seq)
+			for t.view.top = seq; t.view.top > t.shown.First() && used+t.view.rows(t.view.top-1) <= t.view.height; {
+				t.view.top--
+				used += t.view.rows(t.view.top)
+			}
+		}
+	}
+	t.clamp()
+	t.follow = t.atBottom()
+}
+
+// bottomLine returns the last visible line.
+func (t *tabData) bottomLine() int {
+	if t.view.rows == nil {
+		return min(t.view.top+t.view.height, t.shown.End()) - 1
+	}
+	if t.atBottom() {
+		return t.shown.End() - 1
+	}
+	seq, used := t.view.top, t.view.rows(t.view.top)
+	for seq+1 < t.shown.End() && used+t.view.rows(seq+1) <= t.view.height {
+		seq++
+		used += t.view.rows(seq)
+	}
+	return seq
+}
+
+// expandTabs replaces tabs with spaces, since their width depends on the
+// terminal.
+func expandTabs(line string) string {
+	if !strings.Contains(line, "\t") {
+		return line
+	}
+	return strings.ReplaceAll(line, "\t", "    ")
+}
+
+// fitLine cuts or pads a rendered line to exactly width cells.
+func fitLine(line string, width int) string {
+	w := ansi.StringWidth(line)
+	if w > width {
+		return ansi.Truncate(line, width, "")
+	}
+	return line + strings.Repeat(" ", width-w)
+}
@@ -0,0 +1,335 @@
+package logsviewer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- Styles ----------------------------------------------------------------
+
+var (
+	matchStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("16")).
+			Background(lipgloss.Color("223"))
+
+	currentMatchStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("16")).
+				Background(lipgloss.Color("215")).
+				Bold(true)
+
+	searchErrorStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("210"))
+)
+
+// search holds the state of the current search, which applies to all tabs.
+type search struct {
+	input   textinput.Model
+	editing bool // the query is being typed
+	regex   bool // interpret the query as a regular expression
+	allTabs bool // n/N continue into other tabs
+	re      *regexp.Regexp
+	err     error // invalid regular expression
+
+	// Where the active tab was when the search started, so that typing
+	// jumps forward from there and cancelling goes back.
+	startOffset int
+	startFollow bool
+}
+
+func newSearch() search {
+	input := textinput.New()
+	input.Prompt = titleStyle.Render("/")
+	return search{input: input}
+}
+
+// active reports whether there is a query to highlight and navigate.
+func (s *search) active() bool {
+	return s.re != nil
+}
+
+// compileQuery turns a query into a regular expression. Plain queries match
+// literally. Matching is case-insensitive unless the query has upper case.
+func compileQuery(query string, regex bool) (*regexp.Regexp, error) {
+	if query == "" {
+		return nil, nil
+	}
+	expr := query
+	if !regex {
+		expr = regexp.QuoteMeta(query)
+	}
+	if !strings.ContainsFunc(query, unicode.IsUpper) {
+		expr = "(?i)" + expr
+	}
+	return regexp.Compile(expr)
+}
+
+// findMatches recomputes the shown lines of the tab that match the search.
+func (m *Model) findMatches(t *tabData) {
+	t.matches = t.matches[:0]
+	t.match = -1
+	if m.search.re == nil {
+		return
+	}
+	for seq := t.shown.First(); seq < t.shown.End(); seq++ {
+		if m.search.re.MatchString(m.lineText(t.shown.At(seq))) {
+			t.matches = append(t.matches, seq)
+		}
+	}
+}
+
+// dropMatches forgets the matches that were dropped from the shown lines.
+func (t *tabData) dropMatches() {
+	n := 0
+	for n < len(t.matches) && t.matches[n] < t.shown.First() {
+		n++
+	}
+	if n == 0 {
+		return
+	}
+	t.matches = t.matches[n:]
+	if t.match >= 0 {
+		t.match -= n
+		if t.match < 0 && len(t.matches) > 0 {
+			t.match = 0
+		}
+	}
+}
+
+// highlight renders every match of re in line, with the current match
+// standing out.
+func highlight(line string, re *regexp.Regexp, current bool) string {
+	style := matchStyle
+	if current {
+		style = currentMatchStyle
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range re.FindAllStringIndex(line, -1) {
+		if loc[0] == loc[1] {
+			continue
+		}
+		b.WriteString(line[last:loc[0]])
+		b.WriteString(style.Render(line[loc[0]:loc[1]]))
+		last = loc[1]
+	}
+	b.WriteString(line[last:])
+	return b.String()
+}
+
+// startSearch opens the query input.
+func (m *Model) startSearch() tea.Cmd {
+	tab := &m.tabs[m.active]
+	m.search.editing = true
+	m.search.startOffset = tab.view.top
+	m.search.startFollow = tab.follow
+	m.search.input.SetValue("")
+	m.setQuery()
+	return m.search.input.Focus()
+}
+
+// updateSearch handles keys while the query is being typed.
+func (m *Model) updateSearch(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		m.search.editing = false
+		m.search.input.Blur()
+		if m.search.input.Value() == "" {
+			m.clearSearch()
+		}
+		return nil
+	case "esc", "ctrl+c":
+		m.search.editing = false
+		m.search.input.Blur()
+		m.clearSearch()
+		tab := &m.tabs[m.active]
+		tab.follow = m.search.startFollow
+		tab.view.top = m.search.startOffset
+		m.syncViewport()
+		return nil
+	case "ctrl+r":
+		m.search.regex = !m.search.regex
+		m.setQuery()
+		return nil
+	case "tab":
+		m.search.allTabs = !m.search.allTabs
+		m.setQuery()
+		return nil
+	}
+
+	prev := m.search.input.Value()
+	var cmd tea.Cmd
+	m.search.input, cmd = m.search.input.Update(msg)
+	if m.search.input.Value() != prev {
+		m.setQuery()
+	}
+	return cmd
+}
+
+// setQuery recompiles the query, recomputes the matches of every tab and
+// jumps to the first match from where the search started.
+func (m *Model) setQuery() {
+	re, err := compileQuery(m.search.input.Value(), m.search.regex)
+	m.search.re, m.search.err = re, err
+	for i := range m.tabs {
+		m.findMatches(&m.tabs[i])
+	}
+	if re == nil {
+		m.syncViewport()
+		return
+	}
+
+	tab := &m.tabs[m.active]
+	for j, line := range tab.matches {
+		if line >= m.search.startOffset {
+			tab.match = j
+			m.showMatch()
+			return
+		}
+	}
+	if m.search.allTabs && m.nextTabWithMatch(1) {
+		return
+	}
+	if len(tab.matches) > 0 {
+		tab.match = 0
+		m.showMatch()
+		return
+	}
+	m.syncViewport()
+}
+
+// clearSearch removes the query and all highlighting.
+func (m *Model) clearSearch() {
+	m.search.input.SetValue("")
+	m.search.re = nil
+	m.search.err = nil
+	for i := range m.tabs {
+		m.findMatches(&m.tabs[i])
+	}
+	m.syncViewport()
+}
+
+// nextMatch moves to the next (dir 1) or previous (dir -1) match. At the end
+// of the active tab it continues in the next tab with matches if the search
+// spans all tabs, or else wraps around.
+func (m *Model) nextMatch(dir int) {
+	tab := &m.tabs[m.active]
+	if len(tab.matches) > 0 && tab.match < 0 {
+		// No current match yet (e.g. after new lines arrived); start from
+		// what is on screen.
+		tab.match = len(tab.matches) - 1
+		if dir > 0 {
+			tab.match = 0
+		}
+		for j, line := range tab.matches {
+			if line >= tab.view.top {
+				tab.match = j
+				if dir < 0 && j > 0 {
+					tab.match = j - 1
+				}
+				break
+			}
+		}
+		m.showMatch()
+		return
+	}
+
+	next := tab.match + dir
+	if next >= 0 && next < len(tab.matches) {
+		tab.match = next
+		m.showMatch()
+		return
+	}
+	if m.search.allTabs && m.nextTabWithMatch(dir) {
+		return
+	}
+	if len(tab.matches) > 0 {
+		tab.match = (next + len(tab.matches)) % len(tab.matches)
+		m.showMatch()
+	}
+}
+
+// nextTabWithMatch switches to the next tab in direction dir that has a
+// match, selecting its first (or, going backwards, last) match. It reports
+// whether such a tab was found.
+func (m *Model) nextTabWithMatch(dir int) bool {
+	n := len(m.tabs)
+	for step := 1; step < n; step++ {
+		i := ((m.active+dir*step)%n + n) % n
+		if len(m.tabs[i].matches) == 0 {
+			continue
+		}
+		m.setActive(i)
+		tab := &m.tabs[i]
+		tab.match = 0
+		if dir < 0 {
+			tab.match = len(tab.matches) - 1
+		}
+		m.showMatch()
+		return true
+	}
+	return false
+}
+
+// showMatch scrolls the active tab so that its current match is centered,
+// and stops following.
+func (m *Model) showMatch() {
+	tab := &m.tabs[m.active]
+	tab.follow = false
+	if tab.match >= 0 {
+		tab.center(tab.matches[tab.match])
+	}
+	m.syncViewport()
+}
+
+// searchStatus renders the search part of the help line.
+func (m *Model) searchStatus() string {
+	var parts []string
+	if m.search.editing {
+		parts = append(parts, m.search.input.View())
+	} else {
+		parts = append(parts, titleStyle.Render("/")+m.search.input.Value())
+	}
+
+	if m.search.err != nil {
+		parts = append(parts, searchErrorStyle.Render("invalid regex"))
+	} else if m.search.active() {
+		tab := &m.tabs[m.active]
+		counter := fmt.Sprintf("%d/%d", tab.match+1, len(tab.matches))
+		if tab.match < 0 {
+			counter = fmt.Sprintf("-/%d", len(tab.matches))
+		}
+		if m.search.allTabs {
+			total := 0
+			for i := range m.tabs {
+				total += len(m.tabs[i].matches)
+			}
+			counter += fmt.Sprintf(" (%d in all tabs)", total)
+		}
+		parts = append(parts, titleStyle.Render(counter))
+	}
+
+	var modes []string
+	if m.search.regex {
+		modes = append(modes, "regex")
+	}
+	if m.search.allTabs {
+		modes = append(modes, "all tabs")
+	}
+	if len(modes) > 0 {
+		parts = append(parts, "["+strings.Join(modes, ", ")+"]")
+	}
+
+	if m.search.editing {
+		parts = append(parts, helpStyle.Render("enter: done  esc: cancel  ctrl+r: regex  tab: all tabs"))
+	} else {
+		parts = append(parts, helpStyle.Render("n/N: next/prev  /: new search  esc: clear"))
+	}
+	return strings.Join(parts, "  ")
+}
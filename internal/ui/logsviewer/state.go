@@ -0,0 +1,130 @@
+package logsviewer
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// TabUpdate reports the container state of a tab, from Options.Watch.
+type TabUpdate struct {
+	Name  string // tab name; a tab is added if there is none by this name
+	Group string // group of an added tab
+	State string // container state, e.g. "running", "exited", "restarting"
+}
+
+// tabUpdateMsg delivers a TabUpdate from Options.Watch.
+type tabUpdateMsg TabUpdate
+
+// watchFailedMsg signals that Options.Watch has stopped with an error.
+type watchFailedMsg struct {
+	err error
+}
+
+// stateStyle colors a container state in the tab bar.
+func stateStyle(state string) lipgloss.Style {
+	color := lipgloss.Color("203")
+	switch state {
+	case "running":
+		color = lipgloss.Color("114")
+	case "restarting", "created", "paused":
+		color = lipgloss.Color("221")
+	}
+	return lipgloss.NewStyle().Foreground(color)
+}
+
+// updateTab applies an update from Options.Watch: it adds the tab if it is
+// new, and re-attaches to its logs when its container runs again.
+func (m *Model) updateTab(u TabUpdate) {
+	i := slices.IndexFunc(m.tabs, func(t tabData) bool {
+		return !t.merged && t.name == u.Name
+	})
+	if i < 0 {
+		m.addTab(TabInfo{Name: u.Name, Group: u.Group}, u.State)
+		return
+	}
+
+	t := &m.tabs[i]
+	prev := t.state
+	t.state = u.State
+	if u.State != "running" || prev == "running" {
+		return
+	}
+	if !t.tailing {
+		m.attach(i)
+	} else if prev != "" {
+		// The container was restarted before the log process noticed that
+		// the previous one stopped; re-attach once it has.
+		t.reattach = true
+	}
+}
+
+// addTab adds a tab for a service that appeared after startup, and follows
+// its logs. The "All" tab, if there is one, includes the new tab.
+func (m *Model) addTab(ti TabInfo, state string) {
+	color, ok := m.colors[ti.Group]
+	if !ok {
+		color = groupColors[len(m.colors)%len(groupColors)]
+		m.colors[ti.Group] = color
+	}
+	t := newTab(ti, color)
+	t.state = state
+	m.tabs = append(m.tabs, t)
+	m.cmds = append(m.cmds, nil)
+	m.readers = append(m.readers, nil)
+	if m.tabs[0].merged {
+		m.setLabels()
+	}
+	m.attach(len(m.tabs) - 1)
+}
+
+// processExited records that the log process of a tab has exited, and
+// re-attaches if its container was restarted in the meantime.
+func (m *Model) processExited(tab int, err error) {
+	t := &m.tabs[tab]
+	t.tailing = false
+	t.exitedAt = time.Now()
+
+	suffix := " [process exited]"
+	if err != nil {
+		suffix = fmt.Sprintf(" [process exited: %v]", err)
+	}
+	m.appendLine(tab, suffix)
+
+	if t.reattach {
+		t.reattach = false
+		m.attach(tab)
+	}
+}
+
+// attach starts a log process for a tab, continuing from where the previous
+// one stopped.
+func (m *Model) attach(tab int) {
+	t := &m.tabs[tab]
+	cmd, err := m.backend.LogsCmd(t.name, t.resumeTime())
+	if err != nil {
+		m.appendLine(tab, fmt.Sprintf(" [failed to attach: %v]", err))
+		return
+	}
+	if !t.exitedAt.IsZero() {
+		m.appendLine(tab, " [re-attached]")
+	}
+	m.cmds[tab] = cmd
+	t.tailing = true
+	m.tailLogs(tab, cmd)
+}
+
+// resumeTime returns when a re-attached log process should start: just
+// after the last line received. The wall clock when the previous process
+// exited can be later than lines a restarted container has already logged,
+// or skewed against the daemon's clock. The exit time is a fallback for logs
+// without timestamps, and is zero before the first process has exited.
+func (t *tabData) resumeTime() time.Time {
+	if t.exitedAt.IsZero() || t.lastTime.IsZero() {
+		return t.exitedAt
+	}
+	// Since is inclusive, so skip the line already received.
+	return t.lastTime.Add(time.Nanosecond)
+}
@@ -0,0 +1,48 @@
+package logsviewer
+
+import (
+	"strings"
+	"time"
+)
+
+// gutterWidth is the width of the timestamp gutter, including its padding.
+const gutterWidth = len("15:04:05.000 ")
+
+// splitTimestamp takes the RFC 3339 timestamp off the start of a line, or
+// off its text after a "name | " prefix as in "docker compose logs
+// --timestamps". It returns a zero time for lines without one.
+func splitTimestamp(line string) (time.Time, string) {
+	start := 0
+	if i := strings.Index(line, "| "); i >= 0 {
+		start = i + 2
+	}
+	for _, at := range []int{start, 0} {
+		end := strings.IndexByte(line[at:], ' ')
+		if end < 0 {
+			continue
+		}
+		if t, err := time.Parse(time.RFC3339Nano, line[at:at+end]); err == nil {
+			return t, line[:at] + line[at+end+1:]
+		}
+	}
+	return time.Time{}, line
+}
+
+// gutterText returns the timestamp gutter of a line, if shown.
+func (m *Model) gutterText(l logLine) string {
+	if !m.timestamps {
+		return ""
+	}
+	if l.time.IsZero() {
+		return strings.Repeat(" ", gutterWidth)
+	}
+	return l.time.Local().Format("15:04:05.000") + " "
+}
+
+// gutter renders the timestamp gutter of a line, if shown.
+func (m *Model) gutter(l logLine) string {
+	if text := m.gutterText(l); text != "" {
+		return helpStyle.Render(text)
+	}
+	return ""
+}
@@ -0,0 +1,226 @@
+package picker
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxVisible is the maximum number of items shown at once.
+const maxVisible = 10
+
+// ErrCancelled is returned by Run when the user quits without choosing.
+var ErrCancelled = errors.New("selection cancelled")
+
+// --- Styles ----------------------------------------------------------------
+
+var (
+	promptStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("215")).
+			Bold(true)
+
+	selectedStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("215")).
+			Bold(true)
+
+	matchStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("156")).
+			Bold(true)
+
+	detailStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("241"))
+
+	helpStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("241"))
+)
+
+// Item is a single choice in the picker.
+type Item struct {
+	Title  string // matched against the query (e.g. "backend/api")
+	Detail string // shown dimmed after the title (e.g. "running")
+}
+
+// match is an item that matched the current query.
+type match struct {
+	index     int   // index into the item list
+	score     int   // higher is better
+	positions []int // rune positions in the title that matched
+}
+
+// Model is the Bubble Tea model for the fuzzy picker.
+type Model struct {
+	items    []Item
+	input    textinput.Model
+	matches  []match
+	cursor   int
+	chosen   int
+	quitting bool
+}
+
+// New creates a picker over the given items, with an optional initial query.
+func New(prompt string, items []Item, query string) *Model {
+	input := textinput.New()
+	input.Prompt = promptStyle.Render(prompt) + " "
+	input.SetValue(query)
+	input.Focus()
+
+	m := &Model{
+		items:  items,
+		input:  input,
+		chosen: -1,
+	}
+	m.filter()
+	return m
+}
+
+// Init implements tea.Model.
+func (m *Model) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Update handles messages.
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			m.quitting = true
+			return m, tea.Quit
+		case "enter":
+			if len(m.matches) > 0 {
+				m.chosen = m.matches[m.cursor].index
+			}
+			m.quitting = true
+			return m, tea.Quit
+		case "up", "ctrl+p", "ctrl+k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+			return m, nil
+		case "down", "ctrl+n", "ctrl+j":
+			if m.cursor < len(m.matches)-1 {
+				m.cursor++
+			}
+			return m, nil
+		}
+	}
+
+	prev := m.input.Value()
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	if m.input.Value() != prev {
+		m.filter()
+	}
+	return m, cmd
+}
+
+// filter recomputes the matches for the current query, best first.
+func (m *Model) filter() {
+	query := m.input.Value()
+	m.matches = m.matches[:0]
+	for i, item := range m.items {
+		if score, positions, ok := fuzzyMatch(query, item.Title); ok {
+			m.matches = append(m.matches, match{index: i, score: score, positions: positions})
+		}
+	}
+	slices.SortStableFunc(m.matches, func(a, b match) int {
+		return b.score - a.score
+	})
+	m.cursor = 0
+}
+
+// fuzzyMatch reports whether all runes of query appear in order in s,
+// ignoring case. Consecutive matches and matches at word starts score higher.
+func fuzzyMatch(query, s string) (int, []int, bool) {
+	q := []rune(strings.ToLower(query))
+	r := []rune(strings.ToLower(s))
+	if len(q) == 0 {
+		return 0, nil, true
+	}
+
+	var positions []int
+	score := 0
+	qi := 0
+	for i := 0; i < len(r) && qi < len(q); i++ {
+		if r[i] != q[qi] {
+			continue
+		}
+		score++
+		if len(positions) > 0 && positions[len(positions)-1] == i-1 {
+			score += 3
+		}
+		if i == 0 || !unicode.IsLetter(r[i-1]) && !unicode.IsDigit(r[i-1]) {
+			score += 2
+		}
+		positions = append(positions, i)
+		qi++
+	}
+	if qi < len(q) {
+		return 0, nil, false
+	}
+	return score, positions, true
+}
+
+// View renders the picker.
+func (m *Model) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(m.input.View())
+	b.WriteString("\n")
+
+	// Scroll the list so the cursor stays visible.
+	start := max(m.cursor-maxVisible+1, 0)
+	end := min(start+maxVisible, len(m.matches))
+	for i := start; i < end; i++ {
+		mt := m.matches[i]
+		item := m.items[mt.index]
+
+		pointer := "  "
+		base := lipgloss.NewStyle()
+		if i == m.cursor {
+			pointer = selectedStyle.Render("> ")
+			base = selectedStyle
+		}
+
+		b.WriteString(pointer)
+		for j, r := range []rune(item.Title) {
+			if slices.Contains(mt.positions, j) {
+				b.WriteString(matchStyle.Render(string(r)))
+			} else {
+				b.WriteString(base.Render(string(r)))
+			}
+		}
+		if item.Detail != "" {
+			b.WriteString("  " + detailStyle.Render(item.Detail))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(helpStyle.Render(fmt.Sprintf("%d/%d  ↑↓: move  enter: select  esc: cancel", len(m.matches), len(m.items))))
+	return b.String()
+}
+
+// Run shows the picker and blocks until the user chooses an item, returning
+// its index. It returns ErrCancelled if the user quits without choosing.
+func Run(prompt string, items []Item, query string) (int, error) {
+	model := New(prompt, items, query)
+
+	p := tea.NewProgram(model, tea.WithOutput(os.Stderr))
+	if _, err := p.Run(); err != nil {
+		return -1, fmt.Errorf("TUI error: %w", err)
+	}
+
+	if model.chosen < 0 {
+		return -1, ErrCancelled
+	}
+	return model.chosen, nil
+}